@@ -16,6 +16,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/AlexAnd012/-Effective-Mobile.git/internal/app"
 	"github.com/AlexAnd012/-Effective-Mobile.git/internal/config"
@@ -37,6 +38,8 @@ func main() {
 
 	// 2) Логгер
 	log := logging.New(cfg.Log.Level)
+	// тот же логгер для ошибок из хендлеров, у которых нет своего
+	slog.SetDefault(log)
 
 	// 3) БД (pgxpool)
 	pool, err := pgxboot.New(
@@ -55,16 +58,20 @@ func main() {
 
 	// 4) Сервисный слой и хендлеры
 	rp := repo.NewPGRepo(pool)
-	svc := service.New(rp)
+	svc := service.New(rp, cfg.Cost.Rounding)
+	rates := service.NewRates(rp)
 
 	health := handlers.NewHealth(pool)
 	subs := handlers.NewSubHandlers(svc)
+	rateH := handlers.NewRateHandlers(rates)
+	admin := handlers.NewAdminHandlers(svc, cfg.SoftDelete.Retention, cfg.Admin.Token)
 
 	// 5) Роутер
 	root := chi.NewRouter()
 
 	// middleware до любых маршрутов
 	root.Use(middleware.RequestID())
+	root.Use(middleware.Actor()) // created_by/updated_by, пока это request ID
 	root.Use(middleware.Recovery(log))
 	root.Use(middleware.AccessLog(log))
 
@@ -77,7 +84,9 @@ func main() {
 	})
 
 	// API с /healthz, /readyz, /api/v1/...
-	api := router.New(router.Handlers{Health: health, Subs: subs})
+	// Idempotency-Key для POST, ответы хранятся в БД
+	api := router.New(router.Handlers{Health: health, Subs: subs, Rates: rateH, Admin: admin},
+		middleware.Idempotency(rp, cfg.Idempotency.TTL, cfg.Idempotency.Lease))
 	root.Mount("/", api)
 
 	// root передаём в сервер
@@ -94,6 +103,11 @@ func main() {
 	}()
 	log.Info("listening", "addr", cfg.Server.Addr)
 
+	// Фоном чистим просроченные Idempotency-Key
+	bg, stopBg := context.WithCancel(context.Background())
+	defer stopBg()
+	go purgeIdempotencyKeys(bg, rp, cfg.Idempotency.TTL, log)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -103,3 +117,25 @@ func main() {
 	_ = srv.Shutdown(ctx)
 	log.Info("stopped")
 }
+
+// purgeIdempotencyKeys раз в ttl (но не реже раза в час) удаляем просроченные ключи, пока не отменён ctx
+func purgeIdempotencyKeys(ctx context.Context, rp repo.IdempotencyRepository, ttl time.Duration, log *slog.Logger) {
+	every := min(ttl, time.Hour)
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			n, err := rp.PurgeIdempotencyKeys(ctx)
+			if err != nil {
+				log.Error("purge idempotency keys failed", slog.Any("err", err))
+				continue
+			}
+			if n > 0 {
+				log.Info("purged idempotency keys", "count", n)
+			}
+		}
+	}
+}
@@ -15,27 +15,74 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/cost/total": {
+        "/admin/subscriptions/purge": {
+            "post": {
+                "description": "Окончательно удаляет подписки, мягко удалённые раньше срока хранения (DELETED_RETENTION), вместе с историей цен.\nЖурнал изменений сохраняется, в него пишется событие purge.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Purge deleted subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003cADMIN_TOKEN\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PurgeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Нет токена или токен неверный",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "ADMIN_TOKEN не задан, ручки /admin отключены",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/cost/breakdown": {
             "get": {
-                "description": "Сумма стоимостей всех подписок за период (включительно), с фильтрами",
+                "description": "Помесячная разбивка стоимости подписок за период (включительно), с фильтрами и группировкой",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "cost"
                 ],
-                "summary": "Total cost",
+                "summary": "Cost breakdown",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Начало периода, YYYY-MM",
+                        "description": "Начало периода, MM-YYYY",
                         "name": "from",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Конец периода, YYYY-MM",
+                        "description": "Конец периода, MM-YYYY",
                         "name": "to",
                         "in": "query",
                         "required": true
@@ -51,34 +98,74 @@ const docTemplate = `{
                         "description": "Фильтр по названию сервиса",
                         "name": "service_name",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата, ISO 4217, по умолчанию RUB",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Учитывать и мягко удалённые подписки",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Группировка через запятую: service_name, user_id",
+                        "name": "group_by",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.TotalCostResponse"
+                            "$ref": "#/definitions/dto.CostBreakdownResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpx.ErrorResponse"
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
                         }
                     }
                 }
             }
         },
-        "/subscriptions": {
+        "/cost/breakdown/export": {
             "get": {
+                "description": "Потоковая выгрузка помесячной разбивки стоимости, параметры как у /cost/breakdown.\nФормат выбирается по Accept: text/csv (по умолчанию) или application/x-ndjson.",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "cost"
                 ],
-                "summary": "List subscriptions",
+                "summary": "Export cost breakdown",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода, MM-YYYY",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода, MM-YYYY",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Фильтр по UUID пользователя",
@@ -87,20 +174,26 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Фильтр по названию сервиса (ILIKE)",
+                        "description": "Фильтр по названию сервиса",
                         "name": "service_name",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Лимит, по умолчанию 50",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "Валюта результата, ISO 4217, по умолчанию RUB",
+                        "name": "currency",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Смещение, по умолчанию 0",
-                        "name": "offset",
+                        "type": "boolean",
+                        "description": "Учитывать и мягко удалённые подписки",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Группировка через запятую: service_name, user_id",
+                        "name": "group_by",
                         "in": "query"
                     }
                 ],
@@ -110,133 +203,1667 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dto.SubscriptionResponse"
+                                "$ref": "#/definitions/dto.CostBreakdownItem"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
                     }
                 }
-            },
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/cost/compare": {
+            "get": {
+                "description": "Сравнение суммы за период from/to с базовым периодом: итоги, абсолютное и процентное изменение, вклад каждого сервиса.\nБазовый период задаётся явно через compare_from/compare_to или через compare (по умолчанию previous_period).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "cost"
                 ],
-                "summary": "Create subscription",
+                "summary": "Cost comparison",
                 "parameters": [
                     {
-                        "description": "Данные подписки",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.CreateSubscriptionRequest"
-                        }
+                        "type": "string",
+                        "description": "Начало периода, MM-YYYY",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода, MM-YYYY",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "previous_period",
+                            "previous_year"
+                        ],
+                        "type": "string",
+                        "description": "Базовый период: previous_period или previous_year",
+                        "name": "compare",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало базового периода, MM-YYYY",
+                        "name": "compare_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец базового периода, MM-YYYY",
+                        "name": "compare_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по названию сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата, ISO 4217, по умолчанию RUB",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Учитывать и мягко удалённые подписки",
+                        "name": "include_deleted",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                            "$ref": "#/definitions/dto.CostCompareResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpx.ErrorResponse"
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
                         }
                     }
                 }
             }
         },
-        "/subscriptions/{id}": {
+        "/cost/forecast": {
             "get": {
+                "description": "Помесячный прогноз расходов на months месяцев начиная с текущего, с накопленной суммой.\nУчитывает даты окончания, запланированные смены цены, паузы и вводные периоды, бессрочные подписки продлеваются.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "cost"
                 ],
-                "summary": "Get subscription by ID",
+                "summary": "Cost forecast",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Горизонт в месяцах, по умолчанию 12, максимум 120",
+                        "name": "months",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "ID подписки (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Фильтр по UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по названию сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата, ISO 4217, по умолчанию RUB",
+                        "name": "currency",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                            "$ref": "#/definitions/dto.CostForecastResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/httpx.ErrorResponse"
+                            "$ref": "#/definitions/httpx.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Частичное обновление. Пустая строка в end_date снимает дату окончания.",
-                "consumes": [
+            }
+        },
+        "/cost/total": {
+            "get": {
+                "description": "Сумма стоимостей всех подписок за период (включительно), с фильтрами",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "cost"
                 ],
-                "summary": "Update subscription",
+                "summary": "Total cost",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID подписки (UUID)",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Начало периода, MM-YYYY",
+                        "name": "from",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "Поля для обновления",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UpdateSubscriptionRequest"
-                        }
+                        "type": "string",
+                        "description": "Конец периода, MM-YYYY",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по названию сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата, ISO 4217, по умолчанию RUB",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Учитывать и мягко удалённые подписки",
+                        "name": "include_deleted",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TotalCostResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpx.ErrorResponse"
+                            "$ref": "#/definitions/httpx.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/httpx.ErrorResponse"
+                            "$ref": "#/definitions/httpx.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/exchange-rates": {
+            "get": {
+                "description": "Курсы валют к рублю, каждый действует с month до следующего курса",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "subscriptions"
+                    "exchange-rates"
                 ],
-                "summary": "Delete subscription",
+                "summary": "List exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фильтр по валюте, ISO 4217",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ExchangeRateResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/exchange-rates/{currency}/{month}": {
+            "put": {
+                "description": "Устанавливает курс валюты к рублю начиная с месяца",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rates"
+                ],
+                "summary": "Set exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюта, ISO 4217",
+                        "name": "currency",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Месяц начала действия, MM-YYYY",
+                        "name": "month",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Курс",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExchangeRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExchangeRateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "exchange-rates"
+                ],
+                "summary": "Delete exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюта, ISO 4217",
+                        "name": "currency",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Месяц начала действия, MM-YYYY",
+                        "name": "month",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "description": "Без cursor — массив (limit/offset). С cursor или envelope=true — объект {items, total, limit, offset/next_cursor, has_more}.\nПорядок start_date desc, id desc. Заголовки X-Total-Count и Link (RFC 8288) отдаются всегда.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фильтр по UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по названию сервиса (ILIKE)",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Текущая цена (с учётом смен цены) от, включительно",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Текущая цена (с учётом смен цены) до, включительно",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Активна в месяце, MM-YYYY",
+                        "name": "active_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала от, MM-YYYY",
+                        "name": "start_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала до, MM-YYYY",
+                        "name": "start_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создана не раньше, RFC 3339 или YYYY-MM-DD",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создана раньше, RFC 3339 или YYYY-MM-DD (день включительно)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Изменена не раньше, RFC 3339 или YYYY-MM-DD",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Изменена раньше, RFC 3339 или YYYY-MM-DD (день включительно)",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended",
+                            "open-ended"
+                        ],
+                        "type": "string",
+                        "description": "Статус относительно текущего месяца",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка: price (текущая цена), start_date, end_date, service_name, created_at, updated_at, с префиксом - по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит, по умолчанию 50, максимум 200",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение, по умолчанию 0",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор keyset-пагинации (next_cursor), пустой — первая страница",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Отдать объект-страницу вместо массива",
+                        "name": "envelope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Показывать и мягко удалённые",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriptionResponse"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки first/prev/next/last"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Всего записей по фильтрам"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create subscription",
+                "parameters": [
+                    {
+                        "description": "Данные подписки",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSubscriptionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ повтора: повтор с тем же телом получит сохранённый ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия записи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Запрос с этим Idempotency-Key ещё выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд повторить запрос"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key использован с другим телом",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/batch": {
+            "post": {
+                "description": "Каждая запись валидируется как в POST /subscriptions, валидные вставляются одной транзакцией.\nБез best_effort любая невалидная запись отменяет вставку всей пачки (422).\nС best_effort вставляются валидные записи, при частичном успехе ответ 207.\nС dry_run записи только проверяются, ответ 200 со статусами valid/invalid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Batch create subscriptions",
+                "parameters": [
+                    {
+                        "description": "Записи и режим",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchCreateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ повтора: повтор с тем же телом получит сохранённый ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "dry_run",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchCreateResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Все записи созданы",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchCreateResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Часть записей создана",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Ни одна запись не создана",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchCreateResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/export": {
+            "get": {
+                "description": "Потоковая выгрузка всех подписок по фильтрам списка, без лимита. Формат выбирается по Accept:\ntext/csv (по умолчанию) или application/x-ndjson. limit, offset и cursor игнорируются.\nstart_date/end_date — MM-YYYY или YYYY-MM-DD, колонка day_precision говорит, какой из форматов в строке.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Export subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фильтр по UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по названию сервиса (ILIKE)",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Текущая цена (с учётом смен цены) от, включительно",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Текущая цена (с учётом смен цены) до, включительно",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Активна в месяце, MM-YYYY",
+                        "name": "active_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала от, MM-YYYY",
+                        "name": "start_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала до, MM-YYYY",
+                        "name": "start_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создана не раньше, RFC 3339 или YYYY-MM-DD",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Создана раньше, RFC 3339 или YYYY-MM-DD (день включительно)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Изменена не раньше, RFC 3339 или YYYY-MM-DD",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Изменена раньше, RFC 3339 или YYYY-MM-DD (день включительно)",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended",
+                            "open-ended"
+                        ],
+                        "type": "string",
+                        "description": "Статус относительно текущего месяца",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка: price (текущая цена), start_date, end_date, service_name, created_at, updated_at, с префиксом - по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Выгрузить и мягко удалённые",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriptionResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/import": {
+            "post": {
+                "description": "CSV с заголовком: multipart/form-data (поле file) или тело text/csv. Даты MM-YYYY.\nКолонки по умолчанию называются как поля (service_name, price, currency, billing_period, user_id, start_date, end_date),\nдругое имя задаётся параметром col_\u003cполе\u003e. Ошибки отдаются по номерам строк файла.",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Import subscriptions from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV-файл (для multipart/form-data)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Разделитель, по умолчанию ',' (tab — табуляция)",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя для всех строк, если в файле нет колонки user_id",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Заголовок колонки service_name",
+                        "name": "col_service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Заголовок колонки price",
+                        "name": "col_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Заголовок колонки start_date",
+                        "name": "col_start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Заголовок колонки end_date",
+                        "name": "col_end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Заголовок колонки user_id",
+                        "name": "col_user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только проверить строки",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Сохранить валидные строки, даже если часть невалидна",
+                        "name": "best_effort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ повтора: повтор с тем же телом получит сохранённый ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "dry_run",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CSVImportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Все строки созданы",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CSVImportResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Часть строк создана",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CSVImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Файл больше 10 МиБ",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Ни одна строка не создана",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CSVImportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Отдать и мягко удалённую",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия записи, передаётся в If-Match при изменении"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Полная замена. Отсутствующий end_date делает подписку бессрочной.\nУ начавшейся подписки price, currency, billing_period и start_date не меняются (400):\nновая цена — через /price-changes, остальное — новой подпиской.\nС If-Match запись меняется, только если её версия совпадает с ETag, иначе 412.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Update subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag из GET",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Поля для обновления",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия записи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Мягкое удаление: подписка пропадает из списка, Get и расчётов стоимости, но остаётся в БД.\nВернуть её можно через POST /subscriptions/{id}/restore, окончательно удаляет очистка после срока хранения.",
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Delete subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag из GET, при несовпадении версии 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Частичное обновление по JSON Merge Patch (RFC 7396). Отсутствующие поля не меняются, null в end_date снимает дату окончания.\nУ начавшейся подписки price, currency, billing_period и start_date не меняются (400):\nновая цена — через /price-changes, остальное — новой подпиской.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Patch subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag из GET, при несовпадении версии 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatchSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия записи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/cancel": {
+            "post": {
+                "description": "Завершает подписку: end_date — последний оплачиваемый месяц. Остальные поля не меняются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Cancel subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag из GET, при несовпадении версии 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Месяц окончания",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CancelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия записи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/history": {
+            "get": {
+                "description": "Журнал create/update/delete/price_change подписки со состоянием до и после, кто и каким запросом менял.\nДоступен и для удалённой подписки. У подписок, созданных до появления журнала, может быть пустым.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Subscription history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriptionEventResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/pause": {
+            "post": {
+                "description": "Приостанавливает подписку: месяцы from..to включительно не входят в расчёты стоимости.\nБез to пауза длится до POST /subscriptions/{id}/resume. Паузы одной подписки не пересекаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Pause subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Месяцы паузы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PauseRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ повтора: повтор с тем же телом получит сохранённый ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PauseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Пауза пересекается с существующей",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/pauses": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List pauses",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PauseResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/pauses/{pause_id}": {
+            "delete": {
+                "description": "Отменяет паузу целиком, в том числе ещё не начавшуюся. Resume такую паузу не затрагивает.",
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Delete pause",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID паузы (UUID)",
+                        "name": "pause_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/price-changes": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List price changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PriceChangeResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Новая цена подписки начиная с месяца effective_from. Месяцы до него считаются по прежней цене.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Schedule price change",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Цена и месяц начала действия",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SchedulePriceChangeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ повтора: повтор с тем же телом получит сохранённый ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceChangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/restore": {
+            "post": {
+                "description": "Отмена мягкого удаления. Неудалённая подписка возвращается без изменений.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Restore subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag из GET с include_deleted=true, при несовпадении версии 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия записи"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/resume": {
+            "post": {
+                "description": "Возобновляет оплату с месяца from: пауза, в которую он попадает, заканчивается месяцем раньше,\nа пауза, начинающаяся с from, отменяется. Паузы, начинающиеся позже from, не меняются — для них DELETE /pauses/{pause_id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Resume subscription",
                 "parameters": [
                     {
                         "type": "string",
@@ -244,30 +1871,433 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Месяц возобновления",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResumeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "204": {
                         "description": "No Content"
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/httpx.ErrorResponse"
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "В этом месяце подписка не на паузе",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/summary": {
+            "get": {
+                "description": "Сводка для страницы аккаунта одним запросом: активные подписки и run-rate текущего месяца,\nрасходы за всё время, самый дорогой сервис и ближайшие даты окончания. Пользователь без подписок получает нули.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата, ISO 4217, по умолчанию RUB",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserSummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpx.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        }
+    },
+    "definitions": {
+        "dto.BatchCreateRequest": {
+            "type": "object",
+            "properties": {
+                "best_effort": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CreateSubscriptionRequest"
+                    }
+                }
+            }
+        },
+        "dto.BatchCreateResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 98
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchItemResult"
+                    }
+                }
+            }
+        },
+        "dto.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "item": {
+                    "$ref": "#/definitions/dto.SubscriptionResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "invalid",
+                        "skipped",
+                        "valid"
+                    ],
+                    "example": "created"
+                }
+            }
+        },
+        "dto.CSVImportResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 98
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CSVRowResult"
+                    }
+                }
+            }
+        },
+        "dto.CSVRowResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "invalid",
+                        "skipped",
+                        "valid"
+                    ],
+                    "example": "created"
+                }
+            }
+        },
+        "dto.CancelRequest": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "MM-YYYY или YYYY-MM-DD",
+                    "type": "string",
+                    "example": "12-2025"
+                }
+            }
+        },
+        "dto.CostBreakdownItem": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "description": "MM-YYYY",
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "dto.CostBreakdownResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CostBreakdownItem"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 5600
+                }
+            }
+        },
+        "dto.CostCompareItem": {
+            "type": "object",
+            "properties": {
+                "contribution": {
+                    "description": "доля в общем изменении, %; null если общее изменение 0",
+                    "type": "number",
+                    "example": 57.14
+                },
+                "current": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "delta": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "previous": {
+                    "type": "integer",
+                    "example": 800
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                }
+            }
+        },
+        "dto.CostComparePeriod": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "MM-YYYY",
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "to": {
+                    "description": "MM-YYYY",
+                    "type": "string",
+                    "example": "03-2025"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 5600
+                }
+            }
+        },
+        "dto.CostCompareResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "current": {
+                    "$ref": "#/definitions/dto.CostComparePeriod"
+                },
+                "delta": {
+                    "type": "integer",
+                    "example": 700
+                },
+                "delta_percent": {
+                    "description": "null если в базовом периоде 0",
+                    "type": "number",
+                    "example": 14.29
+                },
+                "items": {
+                    "description": "по убыванию |delta|",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CostCompareItem"
+                    }
+                },
+                "previous": {
+                    "$ref": "#/definitions/dto.CostComparePeriod"
+                }
+            }
+        },
+        "dto.CostForecastItem": {
+            "type": "object",
+            "properties": {
+                "cumulative": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "month": {
+                    "description": "MM-YYYY",
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 400
+                }
+            }
+        },
+        "dto.CostForecastResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "from": {
+                    "description": "текущий месяц",
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CostForecastItem"
+                    }
+                },
+                "to": {
+                    "type": "string",
+                    "example": "06-2026"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 5600
+                }
+            }
+        },
         "dto.CreateSubscriptionRequest": {
             "type": "object",
             "properties": {
+                "billing_period": {
+                    "description": "по умолчанию monthly",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "currency": {
+                    "description": "ISO 4217, по умолчанию RUB",
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "end_date": {
-                    "description": "YYYY-MM, опционально",
+                    "description": "последний оплачиваемый месяц или день",
                     "type": "string",
-                    "example": "2026-01"
+                    "example": "01-2026"
+                },
+                "intro_phases": {
+                    "description": "пробный и вводные периоды до обычной цены",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IntroPhase"
+                    }
                 },
                 "price": {
                     "type": "integer",
@@ -278,27 +2308,298 @@ const docTemplate = `{
                     "example": "Yandex Plus"
                 },
                 "start_date": {
-                    "description": "YYYY-MM",
+                    "description": "MM-YYYY или YYYY-MM-DD",
                     "type": "string",
-                    "example": "2025-07"
+                    "example": "07-2025"
                 },
                 "user_id": {
-                    "description": "UUID",
                     "type": "string",
                     "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
                 }
             }
         },
+        "dto.ExchangeRateRequest": {
+            "type": "object",
+            "properties": {
+                "rate": {
+                    "description": "рублей за 1 единицу валюты",
+                    "type": "number",
+                    "example": 92.5
+                }
+            }
+        },
+        "dto.ExchangeRateResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "month": {
+                    "description": "MM-YYYY",
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 92.5
+                }
+            }
+        },
+        "dto.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "required",
+                        "invalid_format",
+                        "invalid_value",
+                        "out_of_range"
+                    ],
+                    "example": "out_of_range"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "price"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "price must be \u003e 0"
+                }
+            }
+        },
+        "dto.IntroPhase": {
+            "type": "object",
+            "properties": {
+                "months": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "dto.PatchSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ]
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "description": "null снимает дату окончания",
+                    "type": "string",
+                    "example": "01-2026"
+                },
+                "intro_phases": {
+                    "description": "null или [] снимает вводные периоды",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 450
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PauseRequest": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "MM-YYYY",
+                    "type": "string",
+                    "example": "08-2025"
+                },
+                "to": {
+                    "description": "MM-YYYY, null — до возобновления",
+                    "type": "string",
+                    "example": "09-2025"
+                }
+            }
+        },
+        "dto.PauseResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "08-2025"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "to": {
+                    "description": "нет — до возобновления",
+                    "type": "string",
+                    "example": "09-2025"
+                }
+            }
+        },
+        "dto.PriceChangeResponse": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "type": "string",
+                    "example": "01-2026"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PurgeResponse": {
+            "type": "object",
+            "properties": {
+                "deleted_before": {
+                    "description": "удалены записи, удалённые раньше этого момента",
+                    "type": "string",
+                    "example": "2025-06-01T00:00:00Z"
+                },
+                "purged": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.ResumeRequest": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "MM-YYYY",
+                    "type": "string",
+                    "example": "10-2025"
+                }
+            }
+        },
+        "dto.SchedulePriceChangeRequest": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "description": "MM-YYYY",
+                    "type": "string",
+                    "example": "01-2026"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 500
+                }
+            }
+        },
+        "dto.SubscriptionEventResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "restore",
+                        "purge",
+                        "price_change",
+                        "pause",
+                        "resume",
+                        "pause_delete"
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-02T08:30:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.SubscriptionResponse": {
             "type": "object",
             "properties": {
+                "billing_period": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:00:00Z"
+                },
+                "created_by": {
+                    "description": "кто создал, пока нет аутентификации — request ID",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "day_precision": {
+                    "description": "даты в формате YYYY-MM-DD, неполные месяцы считаются по дням",
+                    "type": "boolean"
+                },
+                "deleted_at": {
+                    "description": "есть только у мягко удалённых",
+                    "type": "string",
+                    "example": "2025-08-01T10:00:00Z"
+                },
                 "end_date": {
-                    "description": "YYYY-MM",
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "intro_phases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IntroPhase"
+                    }
+                },
                 "price": {
                     "type": "integer"
                 },
@@ -306,11 +2607,23 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "start_date": {
-                    "description": "YYYY-MM",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-02T08:30:00Z"
+                },
+                "updated_by": {
+                    "description": "кто изменил последним",
                     "type": "string"
                 },
                 "user_id": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "то же значение, что в ETag",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
@@ -334,10 +2647,30 @@ const docTemplate = `{
         "dto.UpdateSubscriptionRequest": {
             "type": "object",
             "properties": {
+                "billing_period": {
+                    "description": "по умолчанию monthly",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ]
+                },
+                "currency": {
+                    "description": "ISO 4217, по умолчанию RUB",
+                    "type": "string"
+                },
                 "end_date": {
-                    "description": "YYYY-MM или \"\" чтобы снять окончание",
                     "type": "string"
                 },
+                "intro_phases": {
+                    "description": "отсутствие снимает вводные периоды",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IntroPhase"
+                    }
+                },
                 "price": {
                     "type": "integer"
                 },
@@ -345,19 +2678,141 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "start_date": {
-                    "description": "YYYY-MM",
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "httpx.ErrorResponse": {
+        "dto.UserSummaryResponse": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "active_subscriptions": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "lifetime_spend": {
+                    "description": "списано с начала подписок по текущий месяц",
+                    "type": "integer",
+                    "example": 18400
+                },
+                "monthly_run_rate": {
+                    "description": "цены активных подписок, приведённые к месяцу",
+                    "type": "integer",
+                    "example": 1250
+                },
+                "top_service": {
+                    "description": "null если активных подписок нет",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.UserTopService"
+                        }
+                    ]
+                },
+                "upcoming_ends": {
+                    "description": "по возрастанию end_date",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UserUpcomingEnd"
+                    }
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "dto.UserTopService": {
+            "type": "object",
+            "properties": {
+                "monthly_run_rate": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                }
+            }
+        },
+        "dto.UserUpcomingEnd": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "MM-YYYY или YYYY-MM-DD",
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "2b1c1c1e-6b0e-4a36-9d88-8f1a2b3c4d5e"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                }
+            }
+        },
+        "httpx.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "required",
+                        "invalid_format",
+                        "invalid_value",
+                        "out_of_range"
+                    ],
+                    "example": "out_of_range"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "price"
                 },
                 "message": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "price must be \u003e 0"
+                }
+            }
+        },
+        "httpx.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "price must be \u003e 0"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpx.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "host/abcdef-000001"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Bad Request"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:problem:validation_failed"
                 }
             }
         }
@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
 )
 
 type Config struct {
@@ -34,6 +36,19 @@ type Config struct {
 		Level  string
 		Format string
 	}
+	Idempotency struct {
+		TTL   time.Duration // сколько хранится ответ на Idempotency-Key
+		Lease time.Duration // сколько ключ без ответа занят первым запросом
+	}
+	SoftDelete struct {
+		Retention time.Duration // сколько хранится мягко удалённая подписка до очистки
+	}
+	Cost struct {
+		Rounding domain.Rounding // округление сумм с неполными месяцами до рублей
+	}
+	Admin struct {
+		Token string // Bearer-токен для /admin, пусто — ручки /admin отключены
+	}
 }
 
 func Load() (*Config, error) {
@@ -70,9 +85,35 @@ func Load() (*Config, error) {
 	c.Log.Level = getEnv("LOG_LEVEL", "info")
 	c.Log.Format = getEnv("LOG_FORMAT", "json")
 
+	//Idempotency
+	c.Idempotency.TTL = getEnvDur("IDEMPOTENCY_TTL", 24*time.Hour)
+	c.Idempotency.Lease = getEnvDur("IDEMPOTENCY_LEASE", 2*time.Minute)
+
+	//SoftDelete
+	c.SoftDelete.Retention = getEnvDur("DELETED_RETENTION", 30*24*time.Hour)
+
+	//Cost
+	c.Cost.Rounding = domain.Rounding(getEnv("COST_ROUNDING", string(domain.RoundHalfUp)))
+
+	//Admin
+	c.Admin.Token = os.Getenv("ADMIN_TOKEN")
+
 	if c.DB.DSN == "" {
 		return nil, errors.New("empty DB_DSN")
 	}
+	if c.Idempotency.TTL <= 0 {
+		return nil, errors.New("IDEMPOTENCY_TTL must be positive")
+	}
+	// Аренда короче ответа сервера отдала бы ключ второму запросу, пока первый ещё пишет ответ
+	if c.Idempotency.Lease <= c.Server.WriteTimeout {
+		return nil, errors.New("IDEMPOTENCY_LEASE must be longer than SERVER_WRITE_TIMEOUT")
+	}
+	if c.SoftDelete.Retention < 0 {
+		return nil, errors.New("DELETED_RETENTION must not be negative")
+	}
+	if !c.Cost.Rounding.Valid() {
+		return nil, errors.New("COST_ROUNDING must be one of half_up, down, up")
+	}
 	return &c, nil
 }
 
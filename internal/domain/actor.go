@@ -0,0 +1,34 @@
+package domain
+
+import "context"
+
+type (
+	actorKey     struct{}
+	requestIDKey struct{}
+)
+
+// WithActor кладём в контекст идентификатор вызывающего для created_by/updated_by
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom идентификатор вызывающего из контекста, nil если его нет
+func ActorFrom(ctx context.Context) *string {
+	if a, ok := ctx.Value(actorKey{}).(string); ok && a != "" {
+		return &a
+	}
+	return nil
+}
+
+// WithRequestID кладём в контекст request ID для журнала изменений
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom request ID из контекста, nil если его нет
+func RequestIDFrom(ctx context.Context) *string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		return &id
+	}
+	return nil
+}
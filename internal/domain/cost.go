@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// Rounding правило округления суммы до целых рублей
+type Rounding string
+
+const (
+	RoundHalfUp Rounding = "half_up" // до ближайшего, половина — вверх
+	RoundDown   Rounding = "down"    // отбрасываем копейки
+	RoundUp     Rounding = "up"      // любая часть рубля — целый рубль
+)
+
+// Valid правило из списка поддерживаемых
+func (r Rounding) Valid() bool {
+	switch r {
+	case RoundHalfUp, RoundDown, RoundUp:
+		return true
+	}
+	return false
+}
+
+// ServiceCost сумма по сервису за период
+type ServiceCost struct {
+	ServiceName string
+	Total       int64 // в валюте расчёта
+}
+
+// MonthlyCost строка помесячной разбивки стоимости
+// ServiceName/UserID заполнены только при соответствующей группировке
+type MonthlyCost struct {
+	Month         time.Time // 1-е число месяца, UTC
+	ServiceName   *string
+	UserID        *string
+	Total         int64 // рубли
+	Subscriptions int   // сколько подписок оплачивалось в этом месяце
+}
+
+// UserSummary сводка расходов пользователя на текущий месяц, суммы в валюте расчёта
+type UserSummary struct {
+	ActiveSubscriptions int           // подписки, действующие в текущем месяце и не на паузе
+	MonthlyRunRate      int64         // их цена, приведённая к месяцу
+	LifetimeSpend       int64         // всё списанное с начала подписок по текущий месяц
+	TopService          *ServiceCost  // сервис с наибольшим вкладом в run-rate, nil если активных нет
+	UpcomingEnds        []UpcomingEnd // ближайшие даты окончания
+}
+
+// UpcomingEnd подписка с датой окончания не раньше сегодняшней
+type UpcomingEnd struct {
+	SubscriptionID string
+	ServiceName    string
+	EndDate        time.Time
+	DayPrecision   bool
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// BaseCurrency валюта, к которой хранятся курсы
+const BaseCurrency = "RUB"
+
+// ExchangeRate курс валюты к BaseCurrency, действует с Month до следующего курса
+type ExchangeRate struct {
+	Currency string    // ISO 4217, например USD
+	Month    time.Time // 1-е число месяца, UTC
+	Rate     float64   // сколько BaseCurrency за 1 единицу Currency
+}
+
+// ValidCurrency код валюты из трёх заглавных латинских букв
+func ValidCurrency(c string) bool {
+	if len(c) != 3 {
+		return false
+	}
+	for i := 0; i < len(c); i++ {
+		if c[i] < 'A' || c[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
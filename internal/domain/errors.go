@@ -11,4 +11,61 @@ var (
 
 	// ErrInvalidPrice цена должна быть > 0.
 	ErrInvalidPrice = errors.New("price must be > 0")
+
+	// ErrInvalidBillingPeriod неизвестный период оплаты.
+	ErrInvalidBillingPeriod = errors.New("billing_period must be one of weekly, monthly, quarterly, yearly")
+
+	// ErrInvalidCursor курсор пагинации повреждён или выдан не нами.
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidPriceChange месяц смены цены вне срока подписки.
+	ErrInvalidPriceChange = errors.New("effective_from must be after start_date and not after end_date")
+
+	// ErrInvalidPause пауза вне срока подписки или заканчивается раньше начала.
+	ErrInvalidPause = errors.New("pause must start within the subscription and not end before it starts")
+
+	// ErrPauseOverlap пауза пересекается с уже существующей.
+	ErrPauseOverlap = errors.New("pause overlaps an existing pause")
+
+	// ErrPauseNotFound у подписки нет паузы с таким id.
+	ErrPauseNotFound = errors.New("pause not found")
+
+	// ErrNotPaused в месяце возобновления подписка не на паузе.
+	ErrNotPaused = errors.New("subscription is not paused in this month")
+
+	// ErrInvalidCurrency код валюты не в формате ISO 4217.
+	ErrInvalidCurrency = errors.New("currency must be a 3-letter ISO 4217 code")
+
+	// ErrInvalidRate курс должен быть > 0.
+	ErrInvalidRate = errors.New("rate must be > 0")
+
+	// ErrRateNotFound курс не найден.
+	ErrRateNotFound = errors.New("exchange rate not found")
+
+	// ErrNoExchangeRate нет курса для пересчёта списания в нужную валюту.
+	ErrNoExchangeRate = errors.New("no exchange rate for the charged month")
+
+	// ErrConflict запись изменили после того, как клиент её прочитал (версия не совпала).
+	ErrConflict = errors.New("subscription was modified, version mismatch")
+
+	// ErrInvalidInput значение не подошло по типу или ограничению БД, например id не UUID.
+	ErrInvalidInput = errors.New("invalid input value")
+
+	// ErrReferenceNotFound запись ссылается на другую, которой уже нет.
+	ErrReferenceNotFound = errors.New("referenced record not found")
+
+	// ErrAlreadyExists запись с таким ключом уже есть.
+	ErrAlreadyExists = errors.New("record already exists")
+
+	// ErrUnavailable БД временно недоступна или запрос конфликтует с параллельным, можно повторить.
+	ErrUnavailable = errors.New("storage temporarily unavailable, retry later")
+
+	// ErrTimeout запрос к БД не уложился во время.
+	ErrTimeout = errors.New("storage request timed out")
+
+	// ErrIdempotencyKeyReused Idempotency-Key уже использован с другим запросом.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+	// ErrIdempotencyInProgress первый запрос с этим Idempotency-Key ещё выполняется.
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is still in progress")
 )
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// EventAction тип изменения подписки в журнале
+type EventAction string
+
+const (
+	EventCreate  EventAction = "create"
+	EventUpdate  EventAction = "update"
+	EventDelete  EventAction = "delete"  // мягкое удаление, after — строка с deleted_at
+	EventRestore EventAction = "restore" // отмена мягкого удаления
+	EventPurge   EventAction = "purge"   // окончательное удаление после срока хранения
+
+	EventPriceChange EventAction = "price_change" // смена цены с месяца, before/after — строка смены цены
+	EventPause       EventAction = "pause"        // новая пауза, after — строка паузы
+	EventResume      EventAction = "resume"       // пауза укорочена (before/after) или снята (только before)
+	EventPauseDelete EventAction = "pause_delete" // пауза отменена целиком, before — строка паузы
+)
+
+// SubscriptionEvent запись журнала изменений подписки
+// Before/After — строка таблицы в JSON до и после изменения, nil для create/purge соответственно
+type SubscriptionEvent struct {
+	ID             int64
+	SubscriptionID string
+	Action         EventAction
+	Before         []byte
+	After          []byte
+	Actor          *string
+	RequestID      *string
+	CreatedAt      time.Time
+}
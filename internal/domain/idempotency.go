@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord сохранённый ответ на запрос с Idempotency-Key
+// RequestHash — хеш метода, пути и тела, Status 0 — первый запрос ещё выполняется
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	Status      int
+	Header      map[string]string // заголовки ответа, которые повторяем при replay
+	Body        []byte
+	RetryAfter  time.Duration // пока Status 0 — через сколько истечёт аренда ключа и его можно занять заново
+}
+
+// Done ответ уже сохранён
+func (r *IdempotencyRecord) Done() bool { return r.Status != 0 }
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Pause приостановка подписки: месяцы From..To включительно не оплачиваются
+type Pause struct {
+	ID             string
+	SubscriptionID string
+	From           time.Time  // 1-е число месяца, UTC
+	To             *time.Time // nil — до возобновления
+}
+
+// Validate пауза начинается в сроке подписки и не раньше, чем заканчивается
+// Сравниваем месяцы, так что паузу можно начать с месяца старта подписки с точностью до дня
+func (p *Pause) Validate(s *Subscription) error {
+	if p.From.Before(MonthStart(s.StartDate)) || (s.EndDate != nil && p.From.After(MonthStart(*s.EndDate))) {
+		return ErrInvalidPause
+	}
+	if p.To != nil && p.To.Before(p.From) {
+		return ErrInvalidPause
+	}
+	return nil
+}
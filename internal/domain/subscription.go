@@ -2,15 +2,52 @@ package domain
 
 import "time"
 
+// BillingPeriod период оплаты подписки
+type BillingPeriod string
+
+const (
+	BillingWeekly    BillingPeriod = "weekly"
+	BillingMonthly   BillingPeriod = "monthly"
+	BillingQuarterly BillingPeriod = "quarterly"
+	BillingYearly    BillingPeriod = "yearly"
+)
+
+// Valid проверяет, что период из списка поддерживаемых
+func (p BillingPeriod) Valid() bool {
+	switch p {
+	case BillingWeekly, BillingMonthly, BillingQuarterly, BillingYearly:
+		return true
+	}
+	return false
+}
+
 // Subscription доменная модель подписки (структура для бизнес-логики)
-// Даты храним как первое число месяца в UTC.
+// Даты храним как первое число месяца в UTC, с DayPrecision — как конкретные дни.
 type Subscription struct {
-	ID          string
-	ServiceName string
-	Price       int        // рубли, целое
-	UserID      string     // UUID
-	StartDate   time.Time  // 1-е число месяца, UTC
-	EndDate     *time.Time // nil = бессрочная
+	ID            string
+	ServiceName   string
+	Price         int           // целое, в валюте Currency, за один период оплаты
+	IntroPhases   []IntroPhase  // вводные периоды от StartDate, после них действует Price
+	Currency      string        // ISO 4217, по умолчанию RUB
+	BillingPeriod BillingPeriod // списание раз в период, начиная с StartDate
+	UserID        string        // UUID
+	StartDate     time.Time     // 1-е число месяца, UTC, с DayPrecision — первый день
+	EndDate       *time.Time    // nil = бессрочная, с DayPrecision — последний оплачиваемый день
+	DayPrecision  bool          // даты с точностью до дня, неполные месяцы считаются по дням
+	Version       int           // растёт на 1 при каждом изменении, для If-Match
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CreatedBy     *string    // кто создал, nil если неизвестно
+	UpdatedBy     *string    // кто изменил последним
+	DeletedAt     *time.Time // nil — не удалена, иначе момент мягкого удаления
+}
+
+// IntroPhase вводный период: Months месяцев по цене Price за месяц (0 — бесплатный пробный)
+// Цена фазы заменяет обычную цену и её смены для списаний в этих месяцах, при любом BillingPeriod
+// обычные списания начинаются после всех фаз. Хранится в jsonb, отсюда json-теги
+type IntroPhase struct {
+	Months int `json:"months"`
+	Price  int `json:"price"`
 }
 
 // MonthStart нормализует дату к первому дню месяца (00:00:00 UTC)
@@ -19,7 +56,11 @@ func MonthStart(t time.Time) time.Time {
 }
 
 // Normalize приводит StartDate и  EndDate к первому дню месяца (00:00:00 UTC)
+// Подписку с точностью до дня не трогаем
 func (s *Subscription) Normalize() {
+	if s.DayPrecision {
+		return
+	}
 	s.StartDate = MonthStart(s.StartDate)
 	if s.EndDate != nil {
 		e := MonthStart(*s.EndDate)
@@ -28,13 +69,41 @@ func (s *Subscription) Normalize() {
 }
 
 // Validate проверяет базовые инварианты модели и используем ошибки из файла /internal/domain/errors.go
-// Цена должна быть > 0 и дата начала должна быть до даты конца
+// Цена должна быть > 0, период оплаты и валюта известны и дата начала должна быть до даты конца
+// Возвращает *ValidationError со всеми нарушениями сразу
 func (s *Subscription) Validate() error {
+	var v ValidationError
 	if s.Price <= 0 {
-		return ErrInvalidPrice
+		v.AddErr("price", CodeOutOfRange, ErrInvalidPrice)
+	}
+	if !s.BillingPeriod.Valid() {
+		v.AddErr("billing_period", CodeInvalidValue, ErrInvalidBillingPeriod)
+	}
+	if !ValidCurrency(s.Currency) {
+		v.AddErr("currency", CodeInvalidValue, ErrInvalidCurrency)
 	}
 	if s.EndDate != nil && s.EndDate.Before(s.StartDate) {
-		return ErrInvalidDates
+		v.AddErr("end_date", CodeOutOfRange, ErrInvalidDates)
+	}
+	return v.Err()
+}
+
+// PriceChange новая цена подписки начиная с месяца EffectiveFrom
+// До первой смены действует Subscription.Price
+type PriceChange struct {
+	ID             string
+	SubscriptionID string
+	EffectiveFrom  time.Time // 1-е число месяца, UTC
+	Price          int       // в валюте подписки, за один период оплаты
+}
+
+// Validate цена > 0, месяц смены после начала подписки и не позже её окончания
+func (p *PriceChange) Validate(s *Subscription) error {
+	if p.Price <= 0 {
+		return ErrInvalidPrice
+	}
+	if !p.EffectiveFrom.After(s.StartDate) || (s.EndDate != nil && p.EffectiveFrom.After(*s.EndDate)) {
+		return ErrInvalidPriceChange
 	}
 	return nil
 }
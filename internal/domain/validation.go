@@ -0,0 +1,78 @@
+package domain
+
+import "strings"
+
+// Коды ошибок валидации полей, по ним клиент понимает причину без разбора текста
+const (
+	CodeRequired      = "required"       // поле обязательно
+	CodeInvalidFormat = "invalid_format" // значение не разбирается: UUID, месяц MM-YYYY
+	CodeInvalidValue  = "invalid_value"  // значение не из допустимого набора
+	CodeOutOfRange    = "out_of_range"   // число или дата вне допустимых границ
+)
+
+// FieldError ошибка одного поля
+// Err доменная ошибка-причина, если есть, чтобы errors.Is продолжал работать
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+	Err     error
+}
+
+// ValidationError ошибки всех невалидных полей запроса, а не только первого
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Add добавляем ошибку поля с текстом
+func (e *ValidationError) Add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// AddErr добавляем ошибку поля по доменной ошибке, текст берём из неё
+func (e *ValidationError) AddErr(field, code string, err error) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: err.Error(), Err: err})
+}
+
+// Has есть ли уже ошибка по полю, чтобы не проверять зависящие от него условия
+func (e *ValidationError) Has(field string) bool {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Err nil, если ошибок нет, иначе сама ValidationError
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error тексты всех полей через "; "
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap доменные ошибки полей для errors.Is
+func (e *ValidationError) Unwrap() []error {
+	var errs []error
+	for _, f := range e.Fields {
+		if f.Err != nil {
+			errs = append(errs, f.Err)
+		}
+	}
+	return errs
+}
+
+// Invalid ValidationError с одним полем, для ошибок разбора параметров запроса
+func Invalid(field, code, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Code: code, Message: message}}}
+}
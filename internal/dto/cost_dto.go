@@ -2,11 +2,14 @@ package dto
 
 // TotalCostQuery — параметры запроса для подсчёта суммы.
 // from/to — обязательные месяцы в формате "MM-YYYY".
+// Суммы пересчитываются в currency по курсу месяца списания.
 type TotalCostQuery struct {
-	From        string  `query:"from" example:"01-2025"` // MM-YYYY
-	To          string  `query:"to" example:"12-2025"`   // MM-YYYY
-	UserID      *string `query:"user_id"`
-	ServiceName *string `query:"service_name"`
+	From           string  `query:"from" example:"01-2025"` // MM-YYYY
+	To             string  `query:"to" example:"12-2025"`   // MM-YYYY
+	UserID         *string `query:"user_id"`
+	ServiceName    *string `query:"service_name"`
+	Currency       string  `query:"currency" example:"USD"` // валюта результата, по умолчанию RUB
+	IncludeDeleted bool    `query:"include_deleted"`        // учитывать и мягко удалённые подписки
 }
 
 // TotalCostResponse ответ по суммарной стоимости.
@@ -15,3 +18,87 @@ type TotalCostResponse struct {
 	Currency      string `json:"currency" example:"RUB"`
 	MonthsCounted int    `json:"months_counted" example:"14"`
 }
+
+// CostBreakdownQuery параметры помесячной разбивки, фильтры как в TotalCostQuery
+// group_by — список через запятую: service_name, user_id
+type CostBreakdownQuery struct {
+	TotalCostQuery
+	GroupBy []string `query:"group_by" example:"service_name,user_id"`
+}
+
+// CostBreakdownItem сумма за один месяц (и группу, если задана)
+type CostBreakdownItem struct {
+	Month         string  `json:"month" example:"07-2025"` // MM-YYYY
+	ServiceName   *string `json:"service_name,omitempty" example:"Yandex Plus"`
+	UserID        *string `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Total         int64   `json:"total" example:"400"`
+	Subscriptions int     `json:"subscriptions" example:"1"`
+}
+
+// CostBreakdownResponse ответ помесячной разбивки
+type CostBreakdownResponse struct {
+	Total    int64               `json:"total" example:"5600"`
+	Currency string              `json:"currency" example:"RUB"`
+	Items    []CostBreakdownItem `json:"items"`
+}
+
+// CostForecastQuery параметры прогноза: months месяцев начиная с текущего, фильтры и валюта как в TotalCostQuery
+type CostForecastQuery struct {
+	Months      string  `query:"months" example:"12"` // по умолчанию 12, максимум 120
+	UserID      *string `query:"user_id"`
+	ServiceName *string `query:"service_name"`
+	Currency    string  `query:"currency" example:"RUB"`
+}
+
+// CostForecastItem прогноз на один месяц и накопленная сумма с начала прогноза
+type CostForecastItem struct {
+	Month         string `json:"month" example:"07-2025"` // MM-YYYY
+	Total         int64  `json:"total" example:"400"`
+	Cumulative    int64  `json:"cumulative" example:"1200"`
+	Subscriptions int    `json:"subscriptions" example:"1"`
+}
+
+// CostForecastResponse помесячный прогноз расходов
+type CostForecastResponse struct {
+	From     string             `json:"from" example:"07-2025"` // текущий месяц
+	To       string             `json:"to" example:"06-2026"`
+	Total    int64              `json:"total" example:"5600"`
+	Currency string             `json:"currency" example:"RUB"`
+	Items    []CostForecastItem `json:"items"`
+}
+
+// CostCompareQuery параметры сравнения двух периодов, фильтры и валюта как в TotalCostQuery
+// Базовый период задаётся либо явно через compare_from/compare_to, либо через compare:
+// previous_period (столько же месяцев непосредственно перед from) или previous_year (тот же период годом ранее)
+type CostCompareQuery struct {
+	TotalCostQuery
+	Compare     string `query:"compare" example:"previous_period"` // по умолчанию previous_period
+	CompareFrom string `query:"compare_from" example:"01-2024"`    // MM-YYYY
+	CompareTo   string `query:"compare_to" example:"12-2024"`      // MM-YYYY
+}
+
+// CostComparePeriod период сравнения и сумма за него
+type CostComparePeriod struct {
+	From  string `json:"from" example:"01-2025"` // MM-YYYY
+	To    string `json:"to" example:"03-2025"`   // MM-YYYY
+	Total int64  `json:"total" example:"5600"`
+}
+
+// CostCompareItem вклад сервиса в изменение суммы
+type CostCompareItem struct {
+	ServiceName  string   `json:"service_name" example:"Yandex Plus"`
+	Current      int64    `json:"current" example:"1200"`
+	Previous     int64    `json:"previous" example:"800"`
+	Delta        int64    `json:"delta" example:"400"`
+	Contribution *float64 `json:"contribution" example:"57.14"` // доля в общем изменении, %; null если общее изменение 0
+}
+
+// CostCompareResponse сравнение сумм двух периодов
+type CostCompareResponse struct {
+	Current      CostComparePeriod `json:"current"`
+	Previous     CostComparePeriod `json:"previous"`
+	Delta        int64             `json:"delta" example:"700"`
+	DeltaPercent *float64          `json:"delta_percent" example:"14.29"` // null если в базовом периоде 0
+	Currency     string            `json:"currency" example:"RUB"`
+	Items        []CostCompareItem `json:"items"` // по убыванию |delta|
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "encoding/json"
+
+// Optional поле тела JSON Merge Patch (RFC 7396)
+// Set — поле есть в теле, Null — в теле явный null, иначе значение в Value
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON вызывается только для присутствующих полей, в том числе для null
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	if string(b) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(b, &o.Value)
+}
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptional(t *testing.T) {
+	type body struct {
+		Name  Optional[string] `json:"name"`
+		Price Optional[int]    `json:"price"`
+	}
+	tests := []struct {
+		name      string
+		in        string
+		wantName  Optional[string]
+		wantPrice Optional[int]
+		wantErr   bool
+	}{
+		{name: "absent", in: `{}`},
+		{name: "value", in: `{"name":"Yandex Plus","price":400}`,
+			wantName: Optional[string]{Set: true, Value: "Yandex Plus"}, wantPrice: Optional[int]{Set: true, Value: 400}},
+		{name: "null", in: `{"name":null}`, wantName: Optional[string]{Set: true, Null: true}},
+		{name: "zero value is not null", in: `{"name":"","price":0}`,
+			wantName: Optional[string]{Set: true}, wantPrice: Optional[int]{Set: true}},
+		{name: "wrong type", in: `{"price":"400"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b body
+			err := json.Unmarshal([]byte(tt.in), &b)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %+v, want error", tt.in, b)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.in, err)
+			}
+			if b.Name != tt.wantName || b.Price != tt.wantPrice {
+				t.Errorf("Unmarshal(%s) = %+v, %+v; want %+v, %+v", tt.in, b.Name, b.Price, tt.wantName, tt.wantPrice)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package dto
+
+// ExchangeRateRequest тело запроса на установку курса валюты
+type ExchangeRateRequest struct {
+	Rate float64 `json:"rate" example:"92.5"` // рублей за 1 единицу валюты
+}
+
+// ExchangeRateResponse курс валюты, действует с month до следующего курса
+type ExchangeRateResponse struct {
+	Currency string  `json:"currency" example:"USD"`
+	Month    string  `json:"month" example:"07-2025"` // MM-YYYY
+	Rate     float64 `json:"rate" example:"92.5"`
+}
@@ -1,40 +1,236 @@
 package dto
 
+import "encoding/json"
+
 // CreateSubscriptionRequest тело запроса на создание подписки
 // example чтобы на swagger были примеры
 type CreateSubscriptionRequest struct {
-	ServiceName string  `json:"service_name" example:"Yandex Plus"`
-	Price       int     `json:"price" example:"400"`
-	UserID      string  `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	StartDate   string  `json:"start_date" example:"07-2025"`
-	EndDate     *string `json:"end_date,omitempty" example:"01-2026"`
+	ServiceName   string       `json:"service_name" example:"Yandex Plus"`
+	Price         int          `json:"price" example:"400"`
+	Currency      string       `json:"currency,omitempty" example:"RUB"`                                                   // ISO 4217, по умолчанию RUB
+	BillingPeriod string       `json:"billing_period,omitempty" example:"monthly" enums:"weekly,monthly,quarterly,yearly"` // по умолчанию monthly
+	UserID        string       `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate     string       `json:"start_date" example:"07-2025"`         // MM-YYYY или YYYY-MM-DD
+	EndDate       *string      `json:"end_date,omitempty" example:"01-2026"` // последний оплачиваемый месяц или день
+	IntroPhases   []IntroPhase `json:"intro_phases,omitempty"`               // пробный и вводные периоды до обычной цены
+}
+
+// IntroPhase вводный период: months месяцев по цене price за месяц, 0 — бесплатный пробный
+// При любом billing_period, обычные списания начинаются после всех фаз
+type IntroPhase struct {
+	Months int `json:"months" example:"1"`
+	Price  int `json:"price" example:"0"`
+}
+
+// BatchCreateRequest массовое создание подписок
+// best_effort — вставить валидные записи, даже если часть невалидна
+// dry_run — только проверить записи, ничего не вставляя
+type BatchCreateRequest struct {
+	Items      []CreateSubscriptionRequest `json:"items"`
+	BestEffort bool                        `json:"best_effort" example:"false"`
+	DryRun     bool                        `json:"dry_run" example:"false"`
+}
+
+// Статусы записей в ответе массового создания
+const (
+	BatchStatusCreated = "created" // запись вставлена
+	BatchStatusInvalid = "invalid" // запись не прошла валидацию
+	BatchStatusSkipped = "skipped" // запись валидна, но не вставлена, потому что пачка отменена
+	BatchStatusValid   = "valid"   // dry_run: запись валидна
+)
+
+// FieldError ошибка одного поля записи, те же поля и коды, что в Problem.errors
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Code    string `json:"code" example:"out_of_range" enums:"required,invalid_format,invalid_value,out_of_range"`
+	Message string `json:"message" example:"price must be > 0"`
+}
+
+// BatchItemResult результат по одной записи, Index — позиция в items
+// Error — все ошибки одной строкой, Errors — они же по полям
+type BatchItemResult struct {
+	Index  int                   `json:"index" example:"0"`
+	Status string                `json:"status" example:"created" enums:"created,invalid,skipped,valid"`
+	Error  string                `json:"error,omitempty"`
+	Errors []FieldError          `json:"errors,omitempty"`
+	Item   *SubscriptionResponse `json:"item,omitempty"`
 }
 
-// UpdateSubscriptionRequest частичное обновление
-// Все поля опциональны, пустая строка в EndDate удаляет дату окончания
+// BatchCreateResponse итог массового создания
+type BatchCreateResponse struct {
+	DryRun  bool              `json:"dry_run"`
+	Created int               `json:"created" example:"98"`
+	Failed  int               `json:"failed" example:"2"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// UpdateSubscriptionRequest полная замена подписки (PUT)
+// Обязательны все поля, кроме currency/billing_period, отсутствующий end_date делает подписку бессрочной
 type UpdateSubscriptionRequest struct {
-	ServiceName string  `json:"service_name"`
-	Price       int     `json:"price"`
-	UserID      string  `json:"user_id"`
-	StartDate   string  `json:"start_date"`
-	EndDate     *string `json:"end_date,omitempty"`
+	ServiceName   string       `json:"service_name"`
+	Price         int          `json:"price"`
+	Currency      string       `json:"currency,omitempty"`                                               // ISO 4217, по умолчанию RUB
+	BillingPeriod string       `json:"billing_period,omitempty" enums:"weekly,monthly,quarterly,yearly"` // по умолчанию monthly
+	UserID        string       `json:"user_id"`
+	StartDate     string       `json:"start_date"`
+	EndDate       *string      `json:"end_date,omitempty"`
+	IntroPhases   []IntroPhase `json:"intro_phases,omitempty"` // отсутствие снимает вводные периоды
+}
+
+// PatchSubscriptionRequest частичное обновление по JSON Merge Patch (RFC 7396)
+// Отсутствующие поля не меняются, null в end_date снимает дату окончания
+type PatchSubscriptionRequest struct {
+	ServiceName   Optional[string]       `json:"service_name" swaggertype:"string" example:"Yandex Plus"`
+	Price         Optional[int]          `json:"price" swaggertype:"integer" example:"450"`
+	Currency      Optional[string]       `json:"currency" swaggertype:"string" example:"RUB"`
+	BillingPeriod Optional[string]       `json:"billing_period" swaggertype:"string" enums:"weekly,monthly,quarterly,yearly"`
+	UserID        Optional[string]       `json:"user_id" swaggertype:"string"`
+	StartDate     Optional[string]       `json:"start_date" swaggertype:"string" example:"07-2025"`
+	EndDate       Optional[string]       `json:"end_date" swaggertype:"string" example:"01-2026"` // null снимает дату окончания
+	IntroPhases   Optional[[]IntroPhase] `json:"intro_phases" swaggertype:"array,object"`         // null или [] снимает вводные периоды
 }
 
 // SubscriptionResponse объект, который отдаем наружу
 type SubscriptionResponse struct {
-	ID          string  `json:"id"`
-	ServiceName string  `json:"service_name"`
-	Price       int     `json:"price"`
-	UserID      string  `json:"user_id"`
-	StartDate   string  `json:"start_date"`
-	EndDate     *string `json:"end_date,omitempty"`
+	ID            string       `json:"id"`
+	ServiceName   string       `json:"service_name"`
+	Price         int          `json:"price"`
+	IntroPhases   []IntroPhase `json:"intro_phases,omitempty"`
+	Currency      string       `json:"currency"`
+	BillingPeriod string       `json:"billing_period"`
+	UserID        string       `json:"user_id"`
+	StartDate     string       `json:"start_date"`
+	EndDate       *string      `json:"end_date,omitempty"`
+	DayPrecision  bool         `json:"day_precision"`       // даты в формате YYYY-MM-DD, неполные месяцы считаются по дням
+	Version       int          `json:"version" example:"1"` // то же значение, что в ETag
+	CreatedAt     string       `json:"created_at" example:"2025-07-01T12:00:00Z"`
+	UpdatedAt     string       `json:"updated_at" example:"2025-07-02T08:30:00Z"`
+	CreatedBy     *string      `json:"created_by,omitempty"`                                // кто создал, пока нет аутентификации — request ID
+	UpdatedBy     *string      `json:"updated_by,omitempty"`                                // кто изменил последним
+	DeletedAt     *string      `json:"deleted_at,omitempty" example:"2025-08-01T10:00:00Z"` // есть только у мягко удалённых
 }
 
-// ListQuery параметры фильтрации/пагинации для списка
-// Парсим их из r.URL.Query() в хендлере
+// ListQuery параметры фильтрации/сортировки/пагинации для списка
+// Парсим их из r.URL.Query() в хендлере как есть, разбор и валидация в сервисе
+// Cursor != nil включает keyset-пагинацию, пустая строка — первая страница
+// Envelope — отдать SubscriptionPage вместо массива
 type ListQuery struct {
-	UserID      *string `query:"user_id"`
-	ServiceName *string `query:"service_name"`
-	Limit       int     `query:"limit"`
-	Offset      int     `query:"offset"`
+	UserID         *string `query:"user_id"`
+	ServiceName    *string `query:"service_name"`
+	PriceMin       string  `query:"price_min" example:"100"`
+	PriceMax       string  `query:"price_max" example:"1000"`
+	ActiveAt       string  `query:"active_at" example:"07-2025"`       // MM-YYYY
+	StartFrom      string  `query:"start_from" example:"01-2025"`      // MM-YYYY
+	StartTo        string  `query:"start_to" example:"12-2025"`        // MM-YYYY
+	CreatedFrom    string  `query:"created_from" example:"2025-07-01"` // RFC 3339 или YYYY-MM-DD
+	CreatedTo      string  `query:"created_to" example:"2025-07-31"`
+	UpdatedFrom    string  `query:"updated_from" example:"2025-07-01T00:00:00Z"`
+	UpdatedTo      string  `query:"updated_to"`
+	Status         string  `query:"status" example:"active"` // active, ended, open-ended
+	Sort           string  `query:"sort" example:"-price"`   // поле, с "-" по убыванию
+	Limit          string  `query:"limit"`
+	Offset         string  `query:"offset"`
+	Cursor         *string `query:"cursor"`
+	Envelope       bool    `query:"envelope"`
+	IncludeDeleted bool    `query:"include_deleted"` // показывать и мягко удалённые
+}
+
+// SubscriptionPage страница списка подписок
+// Offset есть в режиме limit/offset, NextCursor передаётся в cursor для следующей страницы
+// NextCursor есть только при сортировке по умолчанию
+type SubscriptionPage struct {
+	Items      []SubscriptionResponse `json:"items"`
+	Total      int64                  `json:"total" example:"812"`
+	Limit      int                    `json:"limit" example:"50"`
+	Offset     *int                   `json:"offset,omitempty" example:"100"`
+	NextCursor *string                `json:"next_cursor,omitempty"`
+	HasMore    bool                   `json:"has_more"`
+}
+
+// SchedulePriceChangeRequest новая цена подписки начиная с месяца effective_from
+type SchedulePriceChangeRequest struct {
+	Price         int    `json:"price" example:"500"`
+	EffectiveFrom string `json:"effective_from" example:"01-2026"` // MM-YYYY
+}
+
+// PriceChangeResponse смена цены подписки
+type PriceChangeResponse struct {
+	ID             string `json:"id"`
+	SubscriptionID string `json:"subscription_id"`
+	EffectiveFrom  string `json:"effective_from" example:"01-2026"`
+	Price          int    `json:"price" example:"500"`
+}
+
+// CancelRequest последний оплачиваемый месяц подписки
+type CancelRequest struct {
+	EndDate string `json:"end_date" example:"12-2025"` // MM-YYYY или YYYY-MM-DD
+}
+
+// PauseRequest приостановка подписки на месяцы from..to включительно
+type PauseRequest struct {
+	From string  `json:"from" example:"08-2025"` // MM-YYYY
+	To   *string `json:"to" example:"09-2025"`   // MM-YYYY, null — до возобновления
+}
+
+// ResumeRequest месяц, с которого подписка снова оплачивается
+type ResumeRequest struct {
+	From string `json:"from" example:"10-2025"` // MM-YYYY
+}
+
+// PauseResponse пауза подписки
+type PauseResponse struct {
+	ID             string  `json:"id"`
+	SubscriptionID string  `json:"subscription_id"`
+	From           string  `json:"from" example:"08-2025"`
+	To             *string `json:"to,omitempty" example:"09-2025"` // нет — до возобновления
+}
+
+// PurgeResponse результат очистки мягко удалённых подписок
+type PurgeResponse struct {
+	Purged        int64  `json:"purged" example:"3"`
+	DeletedBefore string `json:"deleted_before" example:"2025-06-01T00:00:00Z"` // удалены записи, удалённые раньше этого момента
+}
+
+// SubscriptionEventResponse запись журнала изменений подписки
+// before/after — строка подписки до и после изменения, у create нет before, у delete нет after
+// у price_change — строка смены цены, before только при перезаписи смены на тот же месяц
+// у pause/resume/pause_delete — строка паузы до и после
+type SubscriptionEventResponse struct {
+	ID             int64           `json:"id" example:"42"`
+	SubscriptionID string          `json:"subscription_id"`
+	Action         string          `json:"action" example:"update" enums:"create,update,delete,restore,purge,price_change,pause,resume,pause_delete"`
+	Before         json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After          json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Actor          *string         `json:"actor,omitempty"`
+	RequestID      *string         `json:"request_id,omitempty"`
+	CreatedAt      string          `json:"created_at" example:"2025-07-02T08:30:00Z"`
+}
+
+// CSVImportOptions настройки импорта CSV
+// Columns — заголовок колонки для поля (service_name, price, currency, billing_period, user_id, start_date, end_date),
+// по умолчанию заголовок совпадает с названием поля. Даты в формате MM-YYYY
+type CSVImportOptions struct {
+	Columns    map[string]string
+	Delimiter  rune   // по умолчанию ','
+	UserID     string // для всех строк, если колонки user_id нет
+	DryRun     bool
+	BestEffort bool
+}
+
+// CSVRowResult результат по строке CSV, Line — номер строки в файле
+// Error — все ошибки одной строкой, Errors — они же по полям
+type CSVRowResult struct {
+	Line   int          `json:"line" example:"2"`
+	Status string       `json:"status" example:"created" enums:"created,invalid,skipped,valid"`
+	Error  string       `json:"error,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+	ID     string       `json:"id,omitempty"`
+}
+
+// CSVImportResponse итог импорта CSV
+type CSVImportResponse struct {
+	DryRun  bool           `json:"dry_run"`
+	Created int            `json:"created" example:"98"`
+	Failed  int            `json:"failed" example:"2"`
+	Rows    []CSVRowResult `json:"rows"`
 }
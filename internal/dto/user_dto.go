@@ -0,0 +1,26 @@
+package dto
+
+// UserSummaryResponse сводка расходов пользователя для страницы аккаунта
+// Суммы в currency, run-rate и активные подписки — на текущий месяц
+type UserSummaryResponse struct {
+	UserID              string            `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Currency            string            `json:"currency" example:"RUB"`
+	ActiveSubscriptions int               `json:"active_subscriptions" example:"3"`
+	MonthlyRunRate      int64             `json:"monthly_run_rate" example:"1250"` // цены активных подписок, приведённые к месяцу
+	LifetimeSpend       int64             `json:"lifetime_spend" example:"18400"`  // списано с начала подписок по текущий месяц
+	TopService          *UserTopService   `json:"top_service"`                     // null если активных подписок нет
+	UpcomingEnds        []UserUpcomingEnd `json:"upcoming_ends"`                   // по возрастанию end_date
+}
+
+// UserTopService самый дорогой сервис пользователя по run-rate
+type UserTopService struct {
+	ServiceName    string `json:"service_name" example:"Yandex Plus"`
+	MonthlyRunRate int64  `json:"monthly_run_rate" example:"600"`
+}
+
+// UserUpcomingEnd подписка, которая скоро закончится
+type UserUpcomingEnd struct {
+	ID          string `json:"id" example:"2b1c1c1e-6b0e-4a36-9d88-8f1a2b3c4d5e"`
+	ServiceName string `json:"service_name" example:"Yandex Plus"`
+	EndDate     string `json:"end_date" example:"12-2025"` // MM-YYYY или YYYY-MM-DD
+}
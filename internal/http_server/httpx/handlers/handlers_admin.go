@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/http_server/httpx"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/service"
+)
+
+// AdminHandlers служебные ручки обслуживания данных
+// retention — сколько хранятся мягко удалённые подписки до очистки
+// token — Bearer-токен для всех ручек, пустой отключает их
+type AdminHandlers struct {
+	svc       *service.Service
+	retention time.Duration
+	token     string
+}
+
+func NewAdminHandlers(s *service.Service, retention time.Duration, token string) *AdminHandlers {
+	return &AdminHandlers{svc: s, retention: retention, token: token}
+}
+
+// Routes регистрируем служебные ручки, все — только с Authorization: Bearer <ADMIN_TOKEN>
+func (h *AdminHandlers) Routes(r chi.Router) {
+	r.Use(h.auth)
+	r.Post("/subscriptions/purge", h.purge) // окончательное удаление после срока хранения
+}
+
+// auth без настроенного токена — 403, без заголовка или с чужим токеном — 401
+func (h *AdminHandlers) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.token == "" {
+			httpx.ErrorStatus(w, r, http.StatusForbidden, errors.New("admin endpoints are disabled: ADMIN_TOKEN is not set"))
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			httpx.ErrorStatus(w, r, http.StatusUnauthorized, errors.New("missing or invalid admin token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// @Summary      Purge deleted subscriptions
+// @Description  Окончательно удаляет подписки, мягко удалённые раньше срока хранения (DELETED_RETENTION), вместе с историей цен.
+// @Description  Журнал изменений сохраняется, в него пишется событие purge.
+// @Tags         admin
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer <ADMIN_TOKEN>"
+// @Success      200  {object}  dto.PurgeResponse
+// @Failure      401  {object}  httpx.Problem  "Нет токена или токен неверный"
+// @Failure      403  {object}  httpx.Problem  "ADMIN_TOKEN не задан, ручки /admin отключены"
+// @Failure      500  {object}  httpx.Problem
+// @Router       /admin/subscriptions/purge [post]
+func (h *AdminHandlers) purge(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.PurgeDeleted(r.Context(), h.retention)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, out)
+}
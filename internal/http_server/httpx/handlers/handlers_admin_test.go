@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string // ADMIN_TOKEN
+		header string // Authorization
+		want   int
+	}{
+		{name: "disabled without token", header: "Bearer ", want: http.StatusForbidden},
+		{name: "no header", token: "secret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", header: "Bearer other", want: http.StatusUnauthorized},
+		{name: "not bearer", token: "secret", header: "Basic secret", want: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", header: "Bearer secret", want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			h := NewAdminHandlers(nil, time.Hour, tt.token)
+			r.With(h.auth).Post("/", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
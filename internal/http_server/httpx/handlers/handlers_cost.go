@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
 	"github.com/AlexAnd012/-Effective-Mobile.git/internal/http_server/httpx"
@@ -11,19 +12,73 @@ import (
 // @Description  Сумма стоимостей всех подписок за период (включительно), с фильтрами
 // @Tags         cost
 // @Produce      json
-// @Param        from          query  string  true   "Начало периода, MM-YYYY"
-// @Param        to            query  string  true   "Конец периода, MM-YYYY"
-// @Param        user_id       query  string  false  "Фильтр по UUID пользователя"
-// @Param        service_name  query  string  false  "Фильтр по названию сервиса"
+// @Param        from             query  string  true   "Начало периода, MM-YYYY"
+// @Param        to               query  string  true   "Конец периода, MM-YYYY"
+// @Param        user_id          query  string  false  "Фильтр по UUID пользователя"
+// @Param        service_name     query  string  false  "Фильтр по названию сервиса"
+// @Param        currency         query  string  false  "Валюта результата, ISO 4217, по умолчанию RUB"
+// @Param        include_deleted  query  bool    false  "Учитывать и мягко удалённые подписки"
 // @Success      200  {object}  dto.TotalCostResponse
-// @Failure      400  {object}  httpx.ErrorResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
 // @Router       /cost/total [get]
 func (h *SubHandlers) TotalCost(w http.ResponseWriter, r *http.Request) {
-	// Разбор query-параметров
-	// from/to обязательны, user_id/service_name опциональны
-	q := dto.TotalCostQuery{
-		From: r.URL.Query().Get("from"),
-		To:   r.URL.Query().Get("to"),
+	q := costQuery(r)
+
+	// Вызов бизнес-логики из service\subscription и ответ
+	res, err := h.svc.TotalCost(r.Context(), q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	// используем обертку вокруг encoding/json
+	httpx.JSON(w, http.StatusOK, res)
+}
+
+// @Summary      Cost breakdown
+// @Description  Помесячная разбивка стоимости подписок за период (включительно), с фильтрами и группировкой
+// @Tags         cost
+// @Produce      json
+// @Param        from             query  string  true   "Начало периода, MM-YYYY"
+// @Param        to               query  string  true   "Конец периода, MM-YYYY"
+// @Param        user_id          query  string  false  "Фильтр по UUID пользователя"
+// @Param        service_name     query  string  false  "Фильтр по названию сервиса"
+// @Param        currency         query  string  false  "Валюта результата, ISO 4217, по умолчанию RUB"
+// @Param        include_deleted  query  bool    false  "Учитывать и мягко удалённые подписки"
+// @Param        group_by         query  string  false  "Группировка через запятую: service_name, user_id"
+// @Success      200  {object}  dto.CostBreakdownResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /cost/breakdown [get]
+func (h *SubHandlers) CostBreakdown(w http.ResponseWriter, r *http.Request) {
+	q := breakdownQuery(r)
+
+	// Вызов бизнес-логики из service\subscription и ответ
+	res, err := h.svc.CostBreakdown(r.Context(), q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, res)
+}
+
+// @Summary      Cost forecast
+// @Description  Помесячный прогноз расходов на months месяцев начиная с текущего, с накопленной суммой.
+// @Description  Учитывает даты окончания, запланированные смены цены, паузы и вводные периоды, бессрочные подписки продлеваются.
+// @Tags         cost
+// @Produce      json
+// @Param        months        query  int     false  "Горизонт в месяцах, по умолчанию 12, максимум 120"
+// @Param        user_id       query  string  false  "Фильтр по UUID пользователя"
+// @Param        service_name  query  string  false  "Фильтр по названию сервиса"
+// @Param        currency      query  string  false  "Валюта результата, ISO 4217, по умолчанию RUB"
+// @Success      200  {object}  dto.CostForecastResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /cost/forecast [get]
+func (h *SubHandlers) CostForecast(w http.ResponseWriter, r *http.Request) {
+	q := dto.CostForecastQuery{
+		Months:   r.URL.Query().Get("months"),
+		Currency: r.URL.Query().Get("currency"),
 	}
 	if v := r.URL.Query().Get("user_id"); v != "" {
 		q.UserID = &v
@@ -31,13 +86,74 @@ func (h *SubHandlers) TotalCost(w http.ResponseWriter, r *http.Request) {
 	if v := r.URL.Query().Get("service_name"); v != "" {
 		q.ServiceName = &v
 	}
+	res, err := h.svc.CostForecast(r.Context(), q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, res)
+}
 
-	// Вызов бизнес-логики из service\subscription и ответ
-	res, err := h.svc.TotalCost(r.Context(), q)
+// @Summary      Cost comparison
+// @Description  Сравнение суммы за период from/to с базовым периодом: итоги, абсолютное и процентное изменение, вклад каждого сервиса.
+// @Description  Базовый период задаётся явно через compare_from/compare_to или через compare (по умолчанию previous_period).
+// @Tags         cost
+// @Produce      json
+// @Param        from             query  string  true   "Начало периода, MM-YYYY"
+// @Param        to               query  string  true   "Конец периода, MM-YYYY"
+// @Param        compare          query  string  false  "Базовый период: previous_period или previous_year"  Enums(previous_period, previous_year)
+// @Param        compare_from     query  string  false  "Начало базового периода, MM-YYYY"
+// @Param        compare_to       query  string  false  "Конец базового периода, MM-YYYY"
+// @Param        user_id          query  string  false  "Фильтр по UUID пользователя"
+// @Param        service_name     query  string  false  "Фильтр по названию сервиса"
+// @Param        currency         query  string  false  "Валюта результата, ISO 4217, по умолчанию RUB"
+// @Param        include_deleted  query  bool    false  "Учитывать и мягко удалённые подписки"
+// @Success      200  {object}  dto.CostCompareResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /cost/compare [get]
+func (h *SubHandlers) CostCompare(w http.ResponseWriter, r *http.Request) {
+	q := dto.CostCompareQuery{
+		TotalCostQuery: costQuery(r),
+		Compare:        r.URL.Query().Get("compare"),
+		CompareFrom:    r.URL.Query().Get("compare_from"),
+		CompareTo:      r.URL.Query().Get("compare_to"),
+	}
+	res, err := h.svc.CostCompare(r.Context(), q)
 	if err != nil {
-		httpx.Error(w, http.StatusBadRequest, err)
+		httpx.Error(w, r, err)
 		return
 	}
-	// используем обертку вокруг encoding/json
 	httpx.JSON(w, http.StatusOK, res)
 }
+
+// Разбор общих query-параметров расчёта стоимости
+// from/to обязательны, user_id/service_name/currency опциональны
+func costQuery(r *http.Request) dto.TotalCostQuery {
+	q := dto.TotalCostQuery{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+	if v := r.URL.Query().Get("user_id"); v != "" {
+		q.UserID = &v
+	}
+	if v := r.URL.Query().Get("service_name"); v != "" {
+		q.ServiceName = &v
+	}
+	q.Currency = r.URL.Query().Get("currency")
+	q.IncludeDeleted = queryBool(r, "include_deleted", false)
+	return q
+}
+
+// Разбор query-параметров разбивки: общие параметры стоимости и group_by через запятую
+func breakdownQuery(r *http.Request) dto.CostBreakdownQuery {
+	q := dto.CostBreakdownQuery{TotalCostQuery: costQuery(r)}
+	if v := r.URL.Query().Get("group_by"); v != "" {
+		for _, g := range strings.Split(v, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				q.GroupBy = append(q.GroupBy, g)
+			}
+		}
+	}
+	return q
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/http_server/httpx"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// Форматы выгрузки, первый используется по умолчанию
+const (
+	mimeCSV        = "text/csv"
+	mimeNDJSON     = "application/x-ndjson"
+	mimeNDJSONAlt  = "application/ndjson"
+	exportFlushRow = 500 // как часто сбрасываем буфер клиенту
+)
+
+// @Summary      Export subscriptions
+// @Description  Потоковая выгрузка всех подписок по фильтрам списка, без лимита. Формат выбирается по Accept:
+// @Description  text/csv (по умолчанию) или application/x-ndjson. limit, offset и cursor игнорируются.
+// @Description  start_date/end_date — MM-YYYY или YYYY-MM-DD, колонка day_precision говорит, какой из форматов в строке.
+// @Tags         subscriptions
+// @Produce      text/csv,application/x-ndjson
+// @Param        user_id          query  string  false  "Фильтр по UUID пользователя"
+// @Param        service_name     query  string  false  "Фильтр по названию сервиса (ILIKE)"
+// @Param        price_min        query  int     false  "Текущая цена (с учётом смен цены) от, включительно"
+// @Param        price_max        query  int     false  "Текущая цена (с учётом смен цены) до, включительно"
+// @Param        active_at        query  string  false  "Активна в месяце, MM-YYYY"
+// @Param        start_from       query  string  false  "Дата начала от, MM-YYYY"
+// @Param        start_to         query  string  false  "Дата начала до, MM-YYYY"
+// @Param        created_from     query  string  false  "Создана не раньше, RFC 3339 или YYYY-MM-DD"
+// @Param        created_to       query  string  false  "Создана раньше, RFC 3339 или YYYY-MM-DD (день включительно)"
+// @Param        updated_from     query  string  false  "Изменена не раньше, RFC 3339 или YYYY-MM-DD"
+// @Param        updated_to       query  string  false  "Изменена раньше, RFC 3339 или YYYY-MM-DD (день включительно)"
+// @Param        status           query  string  false  "Статус относительно текущего месяца"  Enums(active, ended, open-ended)
+// @Param        sort             query  string  false  "Сортировка: price (текущая цена), start_date, end_date, service_name, created_at, updated_at, с префиксом - по убыванию"
+// @Param        include_deleted  query  bool    false  "Выгрузить и мягко удалённые"
+// @Success      200  {array}   dto.SubscriptionResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      406  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/export [get]
+func (h *SubHandlers) export(w http.ResponseWriter, r *http.Request) {
+	q := listQuery(r)
+	// start_date/end_date в том же формате, что и в API, day_precision отличает день от месяца
+	header := []string{"id", "service_name", "price", "currency", "billing_period", "user_id", "start_date", "end_date",
+		"day_precision", "created_at", "updated_at", "deleted_at"}
+	streamExport(w, r, "subscriptions", header, func(emit func(any, []string) error) error {
+		return h.svc.ExportSubscriptions(r.Context(), q, func(s *dto.SubscriptionResponse) error {
+			var end, deleted string
+			if s.EndDate != nil {
+				end = *s.EndDate
+			}
+			if s.DeletedAt != nil {
+				deleted = *s.DeletedAt
+			}
+			return emit(s, []string{
+				s.ID, s.ServiceName, strconv.Itoa(s.Price), s.Currency, s.BillingPeriod, s.UserID, s.StartDate, end,
+				strconv.FormatBool(s.DayPrecision), s.CreatedAt, s.UpdatedAt, deleted,
+			})
+		})
+	})
+}
+
+// @Summary      Export cost breakdown
+// @Description  Потоковая выгрузка помесячной разбивки стоимости, параметры как у /cost/breakdown.
+// @Description  Формат выбирается по Accept: text/csv (по умолчанию) или application/x-ndjson.
+// @Tags         cost
+// @Produce      text/csv,application/x-ndjson
+// @Param        from             query  string  true   "Начало периода, MM-YYYY"
+// @Param        to               query  string  true   "Конец периода, MM-YYYY"
+// @Param        user_id          query  string  false  "Фильтр по UUID пользователя"
+// @Param        service_name     query  string  false  "Фильтр по названию сервиса"
+// @Param        currency         query  string  false  "Валюта результата, ISO 4217, по умолчанию RUB"
+// @Param        include_deleted  query  bool    false  "Учитывать и мягко удалённые подписки"
+// @Param        group_by         query  string  false  "Группировка через запятую: service_name, user_id"
+// @Success      200  {array}   dto.CostBreakdownItem
+// @Failure      400  {object}  httpx.Problem
+// @Failure      406  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /cost/breakdown/export [get]
+func (h *SubHandlers) CostBreakdownExport(w http.ResponseWriter, r *http.Request) {
+	q := breakdownQuery(r)
+	header := []string{"month", "service_name", "user_id", "total", "subscriptions"}
+	streamExport(w, r, "cost-breakdown", header, func(emit func(any, []string) error) error {
+		return h.svc.ExportBreakdown(r.Context(), q, func(c *dto.CostBreakdownItem) error {
+			var service, user string
+			if c.ServiceName != nil {
+				service = *c.ServiceName
+			}
+			if c.UserID != nil {
+				user = *c.UserID
+			}
+			return emit(c, []string{
+				c.Month, service, user, strconv.FormatInt(c.Total, 10), strconv.Itoa(c.Subscriptions),
+			})
+		})
+	})
+}
+
+// streamExport общая часть выгрузок: выбор формата, заголовки и запись строк по мере чтения
+// run получает emit, которому передаётся строка в двух видах: объект для NDJSON и поля для CSV
+// Пока ничего не записано, ошибка отдаётся обычным JSON, после первой строки соединение обрывается
+func streamExport(w http.ResponseWriter, r *http.Request, name string, header []string, run func(emit func(any, []string) error) error) {
+	format := httpx.Negotiate(r, mimeCSV, mimeNDJSON, mimeNDJSONAlt)
+	if format == "" {
+		httpx.ErrorStatus(w, r, http.StatusNotAcceptable, errors.New("supported formats: text/csv, application/x-ndjson"))
+		return
+	}
+
+	// Выгрузка может идти дольше SERVER_WRITE_TIMEOUT, снимаем дедлайн только для этого ответа
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	var (
+		cw      *csv.Writer
+		enc     *json.Encoder
+		written int
+	)
+	// Заголовки отправляем с первой строкой, чтобы ошибку валидации ещё можно было вернуть как JSON
+	start := func() {
+		ext := "csv"
+		if format == mimeCSV {
+			w.Header().Set("Content-Type", mimeCSV+"; charset=utf-8")
+			cw = csv.NewWriter(w)
+		} else {
+			ext = "ndjson"
+			w.Header().Set("Content-Type", format)
+			enc = json.NewEncoder(w)
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+"."+ext+`"`)
+		w.WriteHeader(http.StatusOK)
+		if cw != nil {
+			_ = cw.Write(header)
+		}
+	}
+	flush := func() error {
+		if cw != nil {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	err := run(func(obj any, rec []string) error {
+		if written == 0 {
+			start()
+		}
+		written++
+		var err error
+		if cw != nil {
+			err = cw.Write(rec)
+		} else {
+			err = enc.Encode(obj)
+		}
+		if err == nil && written%exportFlushRow == 0 {
+			err = flush()
+		}
+		return err
+	})
+	if err != nil {
+		if written == 0 {
+			httpx.Error(w, r, err)
+			return
+		}
+		// Статус уже отправлен, оборванный ответ не даст клиенту принять неполный файл за целый
+		slog.ErrorContext(r.Context(), "export aborted",
+			"err", err,
+			"path", r.URL.Path,
+			"rows", written,
+			"req_id", chimw.GetReqID(r.Context()),
+		)
+		panic(http.ErrAbortHandler)
+	}
+	if written == 0 {
+		start()
+	}
+	_ = flush()
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -46,10 +47,9 @@ func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	if err := h.DB.Ping(ctx); err != nil {
-		httpx.JSON(w, http.StatusServiceUnavailable, map[string]any{
-			"status": "db down",
-			"error":  err.Error(),
-		})
+		// Текст ошибки драйвера только в лог, наружу не отдаём
+		slog.ErrorContext(r.Context(), "readiness: db ping failed", "err", err)
+		httpx.JSON(w, http.StatusServiceUnavailable, map[string]any{"status": "db down"})
 		return
 	}
 	// используем обертку вокруг encoding/json
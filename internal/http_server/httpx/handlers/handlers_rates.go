@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/http_server/httpx"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/service"
+)
+
+// RateHandlers Структура, в которой управление курсами из service.RateService
+type RateHandlers struct{ svc *service.RateService }
+
+func NewRateHandlers(s *service.RateService) *RateHandlers { return &RateHandlers{svc: s} }
+
+// Routes регистрируем ручки курсов валют
+func (h *RateHandlers) Routes(r chi.Router) {
+	r.Get("/", h.list)
+	r.Route("/{currency}/{month}", func(r chi.Router) {
+		r.Put("/", h.set) // создать или перезаписать курс
+		r.Delete("/", h.delete)
+	})
+}
+
+// @Summary      List exchange rates
+// @Description  Курсы валют к рублю, каждый действует с month до следующего курса
+// @Tags         exchange-rates
+// @Produce      json
+// @Param        currency  query  string  false  "Фильтр по валюте, ISO 4217"
+// @Success      200  {array}   dto.ExchangeRateResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /exchange-rates [get]
+func (h *RateHandlers) list(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.List(r.Context(), r.URL.Query().Get("currency"))
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, out)
+}
+
+// @Summary      Set exchange rate
+// @Description  Устанавливает курс валюты к рублю начиная с месяца
+// @Tags         exchange-rates
+// @Accept       json
+// @Produce      json
+// @Param        currency  path  string                   true  "Валюта, ISO 4217"
+// @Param        month     path  string                   true  "Месяц начала действия, MM-YYYY"
+// @Param        input     body  dto.ExchangeRateRequest  true  "Курс"
+// @Success      200  {object}  dto.ExchangeRateResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /exchange-rates/{currency}/{month} [put]
+func (h *RateHandlers) set(w http.ResponseWriter, r *http.Request) {
+	var req dto.ExchangeRateRequest
+	if err := decode(r, &req); err != nil {
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	out, err := h.svc.Set(r.Context(), chi.URLParam(r, "currency"), chi.URLParam(r, "month"), req)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, out)
+}
+
+// @Summary      Delete exchange rate
+// @Tags         exchange-rates
+// @Param        currency  path  string  true  "Валюта, ISO 4217"
+// @Param        month     path  string  true  "Месяц начала действия, MM-YYYY"
+// @Success      204
+// @Failure      404  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /exchange-rates/{currency}/{month} [delete]
+func (h *RateHandlers) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(r.Context(), chi.URLParam(r, "currency"), chi.URLParam(r, "month")); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
-	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"fmt"
 	"github.com/go-chi/chi/v5"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
 	"github.com/AlexAnd012/-Effective-Mobile.git/internal/http_server/httpx"
@@ -21,11 +25,24 @@ func NewSubHandlers(s *service.Service) *SubHandlers { return &SubHandlers{svc:
 // Routes передаём сервис, получаем готовый набор хендлеров
 func (h *SubHandlers) Routes(r chi.Router) {
 	r.Post("/", h.create)
+	r.Post("/batch", h.createBatch) // массовое создание
+	r.Post("/import", h.importCSV)  // импорт из CSV
 	r.Get("/", h.list)
+	r.Get("/export", h.export) // выгрузка CSV/NDJSON, до /{id}
 	r.Route("/{id}", func(r chi.Router) {
 		r.Get("/", h.get)
-		r.Put("/", h.update) // полное обновление записи
+		r.Put("/", h.update)  // полное обновление записи
+		r.Patch("/", h.patch) // частичное обновление, JSON Merge Patch
 		r.Delete("/", h.delete)
+		r.Get("/price-changes", h.listPriceChanges)
+		r.Post("/price-changes", h.schedulePriceChange) // новая цена с месяца
+		r.Get("/history", h.history)                    // журнал изменений
+		r.Post("/restore", h.restore)                   // отмена мягкого удаления
+		r.Post("/cancel", h.cancel)                     // завершить с месяца
+		r.Post("/pause", h.pause)                       // приостановить на месяцы
+		r.Post("/resume", h.resume)                     // возобновить с месяца
+		r.Get("/pauses", h.listPauses)
+		r.Delete("/pauses/{pause_id}", h.deletePause) // отменить паузу целиком
 	})
 }
 
@@ -34,60 +51,242 @@ func (h *SubHandlers) Routes(r chi.Router) {
 // @Accept       json
 // @Produce      json
 // @Param        input  body  dto.CreateSubscriptionRequest  true  "Данные подписки"
+// @Param        Idempotency-Key  header  string  false  "Ключ повтора: повтор с тем же телом получит сохранённый ответ"
 // @Success      201    {object}  dto.SubscriptionResponse
-// @Failure      400    {object}  httpx.ErrorResponse
+// @Header       201    {string}  ETag  "Версия записи"
+// @Failure      400    {object}  httpx.Problem
+// @Failure      409    {object}  httpx.Problem  "Запрос с этим Idempotency-Key ещё выполняется"
+// @Header       409    {integer}  Retry-After  "Через сколько секунд повторить запрос"
+// @Failure      422    {object}  httpx.Problem  "Idempotency-Key использован с другим телом"
+// @Failure      500  {object}  httpx.Problem
 // @Router       /subscriptions [post]
-
 func (h *SubHandlers) create(w http.ResponseWriter, r *http.Request) {
 	// Читаем JSON тела в dto.CreateSubscriptionRequest
 	var req dto.CreateSubscriptionRequest
 	if err := decode(r, &req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, err)
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
 		return
 	}
 	// Вызываем бизнес-логику
 	out, err := h.svc.Create(r.Context(), req)
 	if err != nil {
-		httpx.Error(w, statusByErr(err), err)
+		httpx.Error(w, r, err)
 		return
 	}
+	setETag(w, out.Version)
 	// используем обертку вокруг encoding/json
 	httpx.JSON(w, http.StatusCreated, out)
 }
 
+// @Summary      Batch create subscriptions
+// @Description  Каждая запись валидируется как в POST /subscriptions, валидные вставляются одной транзакцией.
+// @Description  Без best_effort любая невалидная запись отменяет вставку всей пачки (422).
+// @Description  С best_effort вставляются валидные записи, при частичном успехе ответ 207.
+// @Description  С dry_run записи только проверяются, ответ 200 со статусами valid/invalid.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        input  body  dto.BatchCreateRequest  true  "Записи и режим"
+// @Param        Idempotency-Key  header  string  false  "Ключ повтора: повтор с тем же телом получит сохранённый ответ"
+// @Success      200  {object}  dto.BatchCreateResponse  "dry_run"
+// @Success      201  {object}  dto.BatchCreateResponse  "Все записи созданы"
+// @Success      207  {object}  dto.BatchCreateResponse  "Часть записей создана"
+// @Failure      400  {object}  httpx.Problem
+// @Failure      422  {object}  dto.BatchCreateResponse  "Ни одна запись не создана"
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/batch [post]
+func (h *SubHandlers) createBatch(w http.ResponseWriter, r *http.Request) {
+	var req dto.BatchCreateRequest
+	if err := decode(r, &req); err != nil {
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	// Вызываем бизнес-логику
+	out, err := h.svc.CreateBatch(r.Context(), req)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	status := http.StatusCreated
+	switch {
+	case out.DryRun:
+		status = http.StatusOK
+	case out.Created == 0:
+		status = http.StatusUnprocessableEntity
+	case out.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+	httpx.JSON(w, status, out)
+}
+
+// @Summary      Import subscriptions from CSV
+// @Description  CSV с заголовком: multipart/form-data (поле file) или тело text/csv. Даты MM-YYYY.
+// @Description  Колонки по умолчанию называются как поля (service_name, price, currency, billing_period, user_id, start_date, end_date),
+// @Description  другое имя задаётся параметром col_<поле>. Ошибки отдаются по номерам строк файла.
+// @Tags         subscriptions
+// @Accept       mpfd
+// @Accept       text/csv
+// @Produce      json
+// @Param        file              formData  file    false  "CSV-файл (для multipart/form-data)"
+// @Param        delimiter         query     string  false  "Разделитель, по умолчанию ',' (tab — табуляция)"
+// @Param        user_id           query     string  false  "UUID пользователя для всех строк, если в файле нет колонки user_id"
+// @Param        col_service_name  query     string  false  "Заголовок колонки service_name"
+// @Param        col_price         query     string  false  "Заголовок колонки price"
+// @Param        col_start_date    query     string  false  "Заголовок колонки start_date"
+// @Param        col_end_date      query     string  false  "Заголовок колонки end_date"
+// @Param        col_user_id       query     string  false  "Заголовок колонки user_id"
+// @Param        dry_run           query     bool    false  "Только проверить строки"
+// @Param        best_effort       query     bool    false  "Сохранить валидные строки, даже если часть невалидна"
+// @Param        Idempotency-Key  header  string  false  "Ключ повтора: повтор с тем же телом получит сохранённый ответ"
+// @Success      200  {object}  dto.CSVImportResponse  "dry_run"
+// @Success      201  {object}  dto.CSVImportResponse  "Все строки созданы"
+// @Success      207  {object}  dto.CSVImportResponse  "Часть строк создана"
+// @Failure      400  {object}  httpx.Problem
+// @Failure      413  {object}  httpx.Problem  "Файл больше 10 МиБ"
+// @Failure      415  {object}  httpx.Problem
+// @Failure      422  {object}  dto.CSVImportResponse  "Ни одна строка не создана"
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/import [post]
+func (h *SubHandlers) importCSV(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, httpx.MaxBodySize)
+	var body io.Reader
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mt {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(httpx.MaxBodySize); err != nil {
+			httpx.ErrorStatus(w, r, httpx.BodyStatus(err), err)
+			return
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			httpx.ErrorStatus(w, r, http.StatusBadRequest, fmt.Errorf("file: %w", err))
+			return
+		}
+		defer f.Close()
+		body = f
+	case "text/csv", "application/csv":
+		body = r.Body
+	default:
+		httpx.ErrorStatus(w, r, http.StatusUnsupportedMediaType, fmt.Errorf("expected multipart/form-data or text/csv"))
+		return
+	}
+
+	opts, err := importOptions(r)
+	if err != nil {
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	// Вызываем бизнес-логику
+	out, err := h.svc.ImportCSV(r.Context(), body, opts)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	status := http.StatusCreated
+	switch {
+	case out.DryRun:
+		status = http.StatusOK
+	case out.Created == 0:
+		status = http.StatusUnprocessableEntity
+	case out.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+	httpx.JSON(w, status, out)
+}
+
 // @Summary      Get subscription by ID
 // @Tags         subscriptions
 // @Produce      json
-// @Param        id   path      string  true  "ID подписки (UUID)"
+// @Param        id               path   string  true   "ID подписки (UUID)"
+// @Param        include_deleted  query  bool    false  "Отдать и мягко удалённую"
 // @Success      200  {object}  dto.SubscriptionResponse
-// @Failure      404  {object}  httpx.ErrorResponse
+// @Header       200  {string}  ETag  "Версия записи, передаётся в If-Match при изменении"
+// @Failure      404  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
 // @Router       /subscriptions/{id} [get]
 func (h *SubHandlers) get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	// Вызываем бизнес-логику
-	out, err := h.svc.Get(r.Context(), id)
+	out, err := h.svc.Get(r.Context(), id, queryBool(r, "include_deleted", false))
 	if err != nil {
-		httpx.Error(w, statusByErr(err), err)
+		httpx.Error(w, r, err)
 		return
 	}
+	setETag(w, out.Version)
 	// используем обертку вокруг encoding/json
 	httpx.JSON(w, http.StatusOK, out)
 }
 
 // @Summary      List subscriptions
+// @Description  Без cursor — массив (limit/offset). С cursor или envelope=true — объект {items, total, limit, offset/next_cursor, has_more}.
+// @Description  Порядок start_date desc, id desc. Заголовки X-Total-Count и Link (RFC 8288) отдаются всегда.
 // @Tags         subscriptions
 // @Produce      json
-// @Param        user_id       query  string  false  "Фильтр по UUID пользователя"
-// @Param        service_name  query  string  false  "Фильтр по названию сервиса (ILIKE)"
-// @Param        limit         query  int     false  "Лимит, по умолчанию 50"
-// @Param        offset        query  int     false  "Смещение, по умолчанию 0"
+// @Param        user_id          query  string  false  "Фильтр по UUID пользователя"
+// @Param        service_name     query  string  false  "Фильтр по названию сервиса (ILIKE)"
+// @Param        price_min        query  int     false  "Текущая цена (с учётом смен цены) от, включительно"
+// @Param        price_max        query  int     false  "Текущая цена (с учётом смен цены) до, включительно"
+// @Param        active_at        query  string  false  "Активна в месяце, MM-YYYY"
+// @Param        start_from       query  string  false  "Дата начала от, MM-YYYY"
+// @Param        start_to         query  string  false  "Дата начала до, MM-YYYY"
+// @Param        created_from     query  string  false  "Создана не раньше, RFC 3339 или YYYY-MM-DD"
+// @Param        created_to       query  string  false  "Создана раньше, RFC 3339 или YYYY-MM-DD (день включительно)"
+// @Param        updated_from     query  string  false  "Изменена не раньше, RFC 3339 или YYYY-MM-DD"
+// @Param        updated_to       query  string  false  "Изменена раньше, RFC 3339 или YYYY-MM-DD (день включительно)"
+// @Param        status           query  string  false  "Статус относительно текущего месяца"  Enums(active, ended, open-ended)
+// @Param        sort             query  string  false  "Сортировка: price (текущая цена), start_date, end_date, service_name, created_at, updated_at, с префиксом - по убыванию"
+// @Param        limit            query  int     false  "Лимит, по умолчанию 50, максимум 200"
+// @Param        offset           query  int     false  "Смещение, по умолчанию 0"
+// @Param        cursor           query  string  false  "Курсор keyset-пагинации (next_cursor), пустой — первая страница"
+// @Param        envelope         query  bool    false  "Отдать объект-страницу вместо массива"
+// @Param        include_deleted  query  bool    false  "Показывать и мягко удалённые"
 // @Success      200  {array}   dto.SubscriptionResponse
+// @Header       200  {integer}  X-Total-Count  "Всего записей по фильтрам"
+// @Header       200  {string}   Link           "Ссылки first/prev/next/last"
+// @Failure      400  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
 // @Router       /subscriptions [get]
 func (h *SubHandlers) list(w http.ResponseWriter, r *http.Request) {
-	// Читаем Query-параметры
+	q := listQuery(r)
+	// Вызываем бизнес-логику
+	out, err := h.svc.List(r.Context(), q)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatInt(out.Total, 10))
+	if links := pageLinks(r, out); len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	// используем обертку вокруг encoding/json
+	// без envelope в режиме offset отдаём массив, как раньше
+	if q.Cursor != nil || q.Envelope {
+		httpx.JSON(w, http.StatusOK, out)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, out.Items)
+}
+
+// Разбор query-параметров списка подписок
+// Значения передаём как есть, разбор и валидация в сервисе
+func listQuery(r *http.Request) dto.ListQuery {
+	qs := r.URL.Query()
 	q := dto.ListQuery{
-		Limit:  queryInt(r, "limit", 50),
-		Offset: queryInt(r, "offset", 0),
+		PriceMin:       qs.Get("price_min"),
+		PriceMax:       qs.Get("price_max"),
+		ActiveAt:       qs.Get("active_at"),
+		StartFrom:      qs.Get("start_from"),
+		StartTo:        qs.Get("start_to"),
+		CreatedFrom:    qs.Get("created_from"),
+		CreatedTo:      qs.Get("created_to"),
+		UpdatedFrom:    qs.Get("updated_from"),
+		UpdatedTo:      qs.Get("updated_to"),
+		Status:         qs.Get("status"),
+		Sort:           qs.Get("sort"),
+		Limit:          qs.Get("limit"),
+		Offset:         qs.Get("offset"),
+		Envelope:       queryBool(r, "envelope", false),
+		IncludeDeleted: queryBool(r, "include_deleted", false),
 	}
 	if v := r.URL.Query().Get("user_id"); v != "" {
 		q.UserID = &v
@@ -95,58 +294,384 @@ func (h *SubHandlers) list(w http.ResponseWriter, r *http.Request) {
 	if v := r.URL.Query().Get("service_name"); v != "" {
 		q.ServiceName = &v
 	}
-	// Вызываем бизнес-логику
-	out, err := h.svc.List(r.Context(), q)
-	if err != nil {
-		httpx.Error(w, http.StatusBadRequest, err)
-		return
+	// Параметр cursor, даже пустой, включает keyset-пагинацию
+	if r.URL.Query().Has("cursor") {
+		v := r.URL.Query().Get("cursor")
+		q.Cursor = &v
 	}
-	// используем обертку вокруг encoding/json
-	httpx.JSON(w, http.StatusOK, out)
+	return q
 }
 
 // @Summary      Update subscription
-// @Description  Частичное обновление. Пустая строка в end_date снимает дату окончания.
+// @Description  Полная замена. Отсутствующий end_date делает подписку бессрочной.
+// @Description  У начавшейся подписки price, currency, billing_period и start_date не меняются (400):
+// @Description  новая цена — через /price-changes, остальное — новой подпиской.
+// @Description  С If-Match запись меняется, только если её версия совпадает с ETag, иначе 412.
 // @Tags         subscriptions
 // @Accept       json
-// @Param        id     path  string                         true  "ID подписки (UUID)"
-// @Param        input  body  dto.UpdateSubscriptionRequest  true  "Поля для обновления"
+// @Param        id        path    string                         true   "ID подписки (UUID)"
+// @Param        If-Match  header  string                         false  "ETag из GET"
+// @Param        input     body    dto.UpdateSubscriptionRequest  true   "Поля для обновления"
 // @Success      204
-// @Failure      400  {object}  httpx.ErrorResponse
-// @Failure      404  {object}  httpx.ErrorResponse
+// @Header       204  {string}  ETag  "Новая версия записи"
+// @Failure      400  {object}  httpx.Problem
+// @Failure      404  {object}  httpx.Problem
+// @Failure      412  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
 // @Router       /subscriptions/{id} [put]
 func (h *SubHandlers) update(w http.ResponseWriter, r *http.Request) {
 	// Читаем JSON тела в dto.UpdateSubscriptionRequest
 	id := chi.URLParam(r, "id")
+	version, err := ifMatch(r)
+	if err != nil {
+		httpx.ErrorStatus(w, r, http.StatusPreconditionFailed, err)
+		return
+	}
 	var req dto.UpdateSubscriptionRequest
 	if err := decode(r, &req); err != nil {
-		httpx.Error(w, http.StatusBadRequest, err)
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
 		return
 	}
 	// Вызываем бизнес-логику
-	if err := h.svc.Update(r.Context(), id, req); err != nil {
-		httpx.Error(w, statusByErr(err), err)
+	out, err := h.svc.Update(r.Context(), id, version, req)
+	if err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
+	setETag(w, out.Version)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// @Summary      Patch subscription
+// @Description  Частичное обновление по JSON Merge Patch (RFC 7396). Отсутствующие поля не меняются, null в end_date снимает дату окончания.
+// @Description  У начавшейся подписки price, currency, billing_period и start_date не меняются (400):
+// @Description  новая цена — через /price-changes, остальное — новой подпиской.
+// @Tags         subscriptions
+// @Accept       json
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Param        id        path    string                        true   "ID подписки (UUID)"
+// @Param        If-Match  header  string                        false  "ETag из GET, при несовпадении версии 412"
+// @Param        input     body    dto.PatchSubscriptionRequest  true   "Изменяемые поля"
+// @Success      200  {object}  dto.SubscriptionResponse
+// @Header       200  {string}  ETag  "Новая версия записи"
+// @Failure      400  {object}  httpx.Problem
+// @Failure      404  {object}  httpx.Problem
+// @Failure      412  {object}  httpx.Problem
+// @Failure      415  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id} [patch]
+func (h *SubHandlers) patch(w http.ResponseWriter, r *http.Request) {
+	// Принимаем application/merge-patch+json и обычный application/json
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mt != "application/merge-patch+json" && mt != "application/json") {
+			httpx.ErrorStatus(w, r, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %q", ct))
+			return
+		}
+	}
+	id := chi.URLParam(r, "id")
+	version, err := ifMatch(r)
+	if err != nil {
+		httpx.ErrorStatus(w, r, http.StatusPreconditionFailed, err)
+		return
+	}
+	var req dto.PatchSubscriptionRequest
+	if err := decode(r, &req); err != nil {
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	// Вызываем бизнес-логику
+	out, err := h.svc.Patch(r.Context(), id, version, req)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	setETag(w, out.Version)
+	httpx.JSON(w, http.StatusOK, out)
+}
+
 // @Summary      Delete subscription
+// @Description  Мягкое удаление: подписка пропадает из списка, Get и расчётов стоимости, но остаётся в БД.
+// @Description  Вернуть её можно через POST /subscriptions/{id}/restore, окончательно удаляет очистка после срока хранения.
 // @Tags         subscriptions
-// @Param        id   path  string  true  "ID подписки (UUID)"
+// @Param        id        path    string  true   "ID подписки (UUID)"
+// @Param        If-Match  header  string  false  "ETag из GET, при несовпадении версии 412"
 // @Success      204
-// @Failure      404  {object}  httpx.ErrorResponse
+// @Failure      404  {object}  httpx.Problem
+// @Failure      412  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
 // @Router       /subscriptions/{id} [delete]
 func (h *SubHandlers) delete(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	version, err := ifMatch(r)
+	if err != nil {
+		httpx.ErrorStatus(w, r, http.StatusPreconditionFailed, err)
+		return
+	}
 	// Вызываем бизнес-логику
-	if err := h.svc.Delete(r.Context(), id); err != nil {
-		httpx.Error(w, statusByErr(err), err)
+	if err := h.svc.Delete(r.Context(), id, version); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary      Schedule price change
+// @Description  Новая цена подписки начиная с месяца effective_from. Месяцы до него считаются по прежней цене.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id     path  string                          true  "ID подписки (UUID)"
+// @Param        input  body  dto.SchedulePriceChangeRequest  true  "Цена и месяц начала действия"
+// @Param        Idempotency-Key  header  string  false  "Ключ повтора: повтор с тем же телом получит сохранённый ответ"
+// @Success      201  {object}  dto.PriceChangeResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      404  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/price-changes [post]
+func (h *SubHandlers) schedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var req dto.SchedulePriceChangeRequest
+	if err := decode(r, &req); err != nil {
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	out, err := h.svc.SchedulePriceChange(r.Context(), id, req)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusCreated, out)
+}
+
+// @Summary      List price changes
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path  string  true  "ID подписки (UUID)"
+// @Success      200  {array}   dto.PriceChangeResponse
+// @Failure      404  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/price-changes [get]
+func (h *SubHandlers) listPriceChanges(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.ListPriceChanges(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, out)
+}
+
+// @Summary      Subscription history
+// @Description  Журнал create/update/delete/price_change подписки со состоянием до и после, кто и каким запросом менял.
+// @Description  Доступен и для удалённой подписки. У подписок, созданных до появления журнала, может быть пустым.
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path  string  true  "ID подписки (UUID)"
+// @Success      200  {array}   dto.SubscriptionEventResponse
+// @Failure      404  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/history [get]
+func (h *SubHandlers) history(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.History(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, out)
+}
+
+// @Summary      Restore subscription
+// @Description  Отмена мягкого удаления. Неудалённая подписка возвращается без изменений.
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id        path    string  true   "ID подписки (UUID)"
+// @Param        If-Match  header  string  false  "ETag из GET с include_deleted=true, при несовпадении версии 412"
+// @Success      200  {object}  dto.SubscriptionResponse
+// @Header       200  {string}  ETag  "Новая версия записи"
+// @Failure      404  {object}  httpx.Problem
+// @Failure      412  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/restore [post]
+func (h *SubHandlers) restore(w http.ResponseWriter, r *http.Request) {
+	version, err := ifMatch(r)
+	if err != nil {
+		httpx.ErrorStatus(w, r, http.StatusPreconditionFailed, err)
+		return
+	}
+	out, err := h.svc.Restore(r.Context(), chi.URLParam(r, "id"), version)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	setETag(w, out.Version)
+	httpx.JSON(w, http.StatusOK, out)
+}
+
+// @Summary      Cancel subscription
+// @Description  Завершает подписку: end_date — последний оплачиваемый месяц. Остальные поля не меняются.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id        path    string             true   "ID подписки (UUID)"
+// @Param        If-Match  header  string             false  "ETag из GET, при несовпадении версии 412"
+// @Param        input     body    dto.CancelRequest  true   "Месяц окончания"
+// @Success      200  {object}  dto.SubscriptionResponse
+// @Header       200  {string}  ETag  "Новая версия записи"
+// @Failure      400  {object}  httpx.Problem
+// @Failure      404  {object}  httpx.Problem
+// @Failure      412  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/cancel [post]
+func (h *SubHandlers) cancel(w http.ResponseWriter, r *http.Request) {
+	version, err := ifMatch(r)
+	if err != nil {
+		httpx.ErrorStatus(w, r, http.StatusPreconditionFailed, err)
+		return
+	}
+	var req dto.CancelRequest
+	if err := decode(r, &req); err != nil {
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	out, err := h.svc.Cancel(r.Context(), chi.URLParam(r, "id"), version, req)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	setETag(w, out.Version)
+	httpx.JSON(w, http.StatusOK, out)
+}
+
+// @Summary      Pause subscription
+// @Description  Приостанавливает подписку: месяцы from..to включительно не входят в расчёты стоимости.
+// @Description  Без to пауза длится до POST /subscriptions/{id}/resume. Паузы одной подписки не пересекаются.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id     path  string            true  "ID подписки (UUID)"
+// @Param        input  body  dto.PauseRequest  true  "Месяцы паузы"
+// @Param        Idempotency-Key  header  string  false  "Ключ повтора: повтор с тем же телом получит сохранённый ответ"
+// @Success      201  {object}  dto.PauseResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      404  {object}  httpx.Problem
+// @Failure      409  {object}  httpx.Problem  "Пауза пересекается с существующей"
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/pause [post]
+func (h *SubHandlers) pause(w http.ResponseWriter, r *http.Request) {
+	var req dto.PauseRequest
+	if err := decode(r, &req); err != nil {
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	out, err := h.svc.Pause(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusCreated, out)
+}
+
+// @Summary      Resume subscription
+// @Description  Возобновляет оплату с месяца from: пауза, в которую он попадает, заканчивается месяцем раньше,
+// @Description  а пауза, начинающаяся с from, отменяется. Паузы, начинающиеся позже from, не меняются — для них DELETE /pauses/{pause_id}.
+// @Tags         subscriptions
+// @Accept       json
+// @Param        id     path  string             true  "ID подписки (UUID)"
+// @Param        input  body  dto.ResumeRequest  true  "Месяц возобновления"
+// @Success      204
+// @Failure      400  {object}  httpx.Problem
+// @Failure      404  {object}  httpx.Problem
+// @Failure      409  {object}  httpx.Problem  "В этом месяце подписка не на паузе"
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/resume [post]
+func (h *SubHandlers) resume(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResumeRequest
+	if err := decode(r, &req); err != nil {
+		httpx.ErrorStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.svc.Resume(r.Context(), chi.URLParam(r, "id"), req); err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary      List pauses
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path  string  true  "ID подписки (UUID)"
+// @Success      200  {array}   dto.PauseResponse
+// @Failure      404  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/pauses [get]
+func (h *SubHandlers) listPauses(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.ListPauses(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, out)
+}
+
+// @Summary      Delete pause
+// @Description  Отменяет паузу целиком, в том числе ещё не начавшуюся. Resume такую паузу не затрагивает.
+// @Tags         subscriptions
+// @Param        id        path  string  true  "ID подписки (UUID)"
+// @Param        pause_id  path  string  true  "ID паузы (UUID)"
+// @Success      204
+// @Failure      404  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /subscriptions/{id}/pauses/{pause_id} [delete]
+func (h *SubHandlers) deletePause(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeletePause(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "pause_id")); err != nil {
+		httpx.Error(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Настройки импорта из query или полей формы
+func importOptions(r *http.Request) (dto.CSVImportOptions, error) {
+	opts := dto.CSVImportOptions{Columns: map[string]string{}, UserID: r.FormValue("user_id")}
+	for key := range r.Form {
+		if f, ok := strings.CutPrefix(key, "col_"); ok {
+			opts.Columns[f] = r.FormValue(key)
+		}
+	}
+	switch d := r.FormValue("delimiter"); {
+	case d == "":
+	case d == "tab" || d == `\t`:
+		opts.Delimiter = '\t'
+	case utf8.RuneCountInString(d) == 1:
+		opts.Delimiter, _ = utf8.DecodeRuneInString(d)
+	default:
+		return opts, fmt.Errorf("delimiter must be a single character")
+	}
+	var err error
+	if opts.DryRun, err = formBool(r, "dry_run"); err != nil {
+		return opts, err
+	}
+	if opts.BestEffort, err = formBool(r, "best_effort"); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// Пустое значение — false, иначе строгий разбор
+func formBool(r *http.Request, name string) (bool, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: expected boolean", name)
+	}
+	return b, nil
+}
+
 // Читаем JSON из тела запроса, DisallowUnknownFields защита от лишних полей/опечаток
 func decode(r *http.Request, v any) error {
 	dec := json.NewDecoder(r.Body)
@@ -154,24 +679,76 @@ func decode(r *http.Request, v any) error {
 	return dec.Decode(v)
 }
 
-// Безопасно парсим целое из query с дефолтом
-func queryInt(r *http.Request, name string, def int) int {
+// setETag версия записи как сильный ETag
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ifMatch ожидаемая версия из If-Match, 0 если заголовка нет или он "*"
+// Слабый ETag (W/) тоже принимаем, версия у записи одна
+func ifMatch(r *http.Request) (int, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	tag := strings.TrimPrefix(v, "W/")
+	if len(tag) > 2 && tag[0] == '"' && tag[len(tag)-1] == '"' {
+		if n, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil && n > 0 {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("If-Match must be a single ETag returned by GET, got %s", v)
+}
+
+// Безопасно парсим bool из query с дефолтом
+func queryBool(r *http.Request, name string, def bool) bool {
 	if v := r.URL.Query().Get(name); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			return n
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
 		}
 	}
 	return def
 }
 
-// Маппим доменные ошибки в HTTP-коды, errors. Is для работы с обернутыми ошибками
-func statusByErr(err error) int {
-	switch {
-	case errors.Is(err, domain.ErrNotFound):
-		return http.StatusNotFound
-	case errors.Is(err, domain.ErrInvalidDates), errors.Is(err, domain.ErrInvalidPrice):
-		return http.StatusBadRequest
-	default:
-		return http.StatusBadRequest
+// Ссылки на соседние страницы списка в формате RFC 8288, query берём из запроса
+func pageLinks(r *http.Request, p *dto.SubscriptionPage) []string {
+	link := func(rel string, set func(q url.Values)) string {
+		q := r.URL.Query()
+		set(q)
+		u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+		return fmt.Sprintf("<%s>; rel=%q", u.String(), rel)
+	}
+	var links []string
+	// keyset: только первая и следующая страница
+	if p.Offset == nil {
+		links = append(links, link("first", func(q url.Values) { q.Set("cursor", "") }))
+		if p.NextCursor != nil {
+			links = append(links, link("next", func(q url.Values) { q.Set("cursor", *p.NextCursor) }))
+		}
+		return links
+	}
+	offsetLink := func(rel string, off int) string {
+		return link(rel, func(q url.Values) {
+			q.Set("limit", strconv.Itoa(p.Limit))
+			q.Set("offset", strconv.Itoa(off))
+		})
+	}
+	offset := *p.Offset
+	links = append(links, offsetLink("first", 0))
+	if offset > 0 {
+		links = append(links, offsetLink("prev", max(offset-p.Limit, 0)))
+	}
+	if p.HasMore {
+		links = append(links, offsetLink("next", offset+p.Limit))
+	}
+	// last — на той же сетке смещений, что и текущая страница: при offset не кратном limit next приводит ровно в неё
+	if p.Total > 0 {
+		total, limit, off := p.Total, int64(p.Limit), int64(offset)
+		last := (total - 1) / limit * limit
+		if off < total {
+			last = off + (total-1-off)/limit*limit
+		}
+		links = append(links, offsetLink("last", int(last)))
 	}
+	return links
 }
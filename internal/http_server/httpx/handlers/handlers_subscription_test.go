@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{name: "absent", header: ""},
+		{name: "any", header: "*"},
+		{name: "any with spaces", header: "  *  "},
+		{name: "strong", header: `"3"`, want: 3},
+		{name: "weak", header: `W/"3"`, want: 3},
+		{name: "spaces around", header: ` "12" `, want: 12},
+		{name: "unquoted", header: "3", wantErr: true},
+		{name: "empty tag", header: `""`, wantErr: true},
+		{name: "zero version", header: `"0"`, wantErr: true},
+		{name: "negative version", header: `"-1"`, wantErr: true},
+		{name: "not a number", header: `"abc"`, wantErr: true},
+		{name: "list of tags", header: `"1", "2"`, wantErr: true},
+		{name: "lowercase weak prefix", header: `w/"3"`, wantErr: true},
+		{name: "weak any", header: "W/*", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			got, err := ifMatch(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ifMatch(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ifMatch(%q) = %d, want %d", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageLinks(t *testing.T) {
+	const base = "/api/v1/subscriptions"
+	next := "abc"
+	tests := []struct {
+		name  string
+		query string
+		page  dto.SubscriptionPage
+		want  []string
+	}{
+		{
+			name: "first page", query: "?limit=10&user_id=u",
+			page: dto.SubscriptionPage{Total: 25, Limit: 10, Offset: ptr(0), HasMore: true},
+			want: []string{
+				`<` + base + `?limit=10&offset=0&user_id=u>; rel="first"`,
+				`<` + base + `?limit=10&offset=10&user_id=u>; rel="next"`,
+				`<` + base + `?limit=10&offset=20&user_id=u>; rel="last"`,
+			},
+		},
+		{
+			name: "middle page", query: "?limit=10&offset=10",
+			page: dto.SubscriptionPage{Total: 25, Limit: 10, Offset: ptr(10), HasMore: true},
+			want: []string{
+				`<` + base + `?limit=10&offset=0>; rel="first"`,
+				`<` + base + `?limit=10&offset=0>; rel="prev"`,
+				`<` + base + `?limit=10&offset=20>; rel="next"`,
+				`<` + base + `?limit=10&offset=20>; rel="last"`,
+			},
+		},
+		{
+			name: "offset not multiple of limit", query: "?limit=10&offset=5",
+			page: dto.SubscriptionPage{Total: 25, Limit: 10, Offset: ptr(5), HasMore: true},
+			want: []string{
+				`<` + base + `?limit=10&offset=0>; rel="first"`,
+				`<` + base + `?limit=10&offset=0>; rel="prev"`,
+				`<` + base + `?limit=10&offset=15>; rel="next"`,
+				`<` + base + `?limit=10&offset=15>; rel="last"`,
+			},
+		},
+		{
+			name: "last page is current", query: "?limit=10&offset=15",
+			page: dto.SubscriptionPage{Total: 25, Limit: 10, Offset: ptr(15)},
+			want: []string{
+				`<` + base + `?limit=10&offset=0>; rel="first"`,
+				`<` + base + `?limit=10&offset=5>; rel="prev"`,
+				`<` + base + `?limit=10&offset=15>; rel="last"`,
+			},
+		},
+		{
+			name: "exact multiple", query: "?limit=10&offset=10",
+			page: dto.SubscriptionPage{Total: 20, Limit: 10, Offset: ptr(10)},
+			want: []string{
+				`<` + base + `?limit=10&offset=0>; rel="first"`,
+				`<` + base + `?limit=10&offset=0>; rel="prev"`,
+				`<` + base + `?limit=10&offset=10>; rel="last"`,
+			},
+		},
+		{
+			name: "offset past the end", query: "?limit=10&offset=40",
+			page: dto.SubscriptionPage{Total: 25, Limit: 10, Offset: ptr(40)},
+			want: []string{
+				`<` + base + `?limit=10&offset=0>; rel="first"`,
+				`<` + base + `?limit=10&offset=30>; rel="prev"`,
+				`<` + base + `?limit=10&offset=20>; rel="last"`,
+			},
+		},
+		{
+			name: "empty", query: "",
+			page: dto.SubscriptionPage{Limit: 50, Offset: ptr(0)},
+			want: []string{`<` + base + `?limit=50&offset=0>; rel="first"`},
+		},
+		{
+			name: "keyset with next", query: "?cursor=",
+			page: dto.SubscriptionPage{Total: 25, Limit: 10, NextCursor: &next, HasMore: true},
+			want: []string{
+				`<` + base + `?cursor=>; rel="first"`,
+				`<` + base + `?cursor=abc>; rel="next"`,
+			},
+		},
+		{
+			name: "keyset last page", query: "?cursor=abc",
+			page: dto.SubscriptionPage{Total: 25, Limit: 10},
+			want: []string{`<` + base + `?cursor=>; rel="first"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", base+tt.query, nil)
+			if got := pageLinks(r, &tt.page); !slices.Equal(got, tt.want) {
+				t.Errorf("pageLinks() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
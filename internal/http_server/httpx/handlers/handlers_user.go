@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/http_server/httpx"
+)
+
+// @Summary      User summary
+// @Description  Сводка для страницы аккаунта одним запросом: активные подписки и run-rate текущего месяца,
+// @Description  расходы за всё время, самый дорогой сервис и ближайшие даты окончания. Пользователь без подписок получает нули.
+// @Tags         users
+// @Produce      json
+// @Param        user_id   path   string  true   "UUID пользователя"
+// @Param        currency  query  string  false  "Валюта результата, ISO 4217, по умолчанию RUB"
+// @Success      200  {object}  dto.UserSummaryResponse
+// @Failure      400  {object}  httpx.Problem
+// @Failure      500  {object}  httpx.Problem
+// @Router       /users/{user_id}/summary [get]
+func (h *SubHandlers) UserSummary(w http.ResponseWriter, r *http.Request) {
+	res, err := h.svc.UserSummary(r.Context(), chi.URLParam(r, "user_id"), r.URL.Query().Get("currency"))
+	if err != nil {
+		httpx.Error(w, r, err)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,63 @@
+package httpx
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Negotiate выбираем из offers тип по заголовку Accept с учётом q-значений
+// Пустой Accept означает первый из offers, если ничего не подошло, возвращаем пустую строку
+func Negotiate(r *http.Request, offers ...string) string {
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	// Для каждого offer q берётся из самого конкретного подходящего диапазона
+	qs := make([]float64, len(offers))
+	specs := make([]int, len(offers))
+	for i := range specs {
+		specs[i] = -1
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		for i, offer := range offers {
+			if spec := matchMedia(mt, offer); spec > specs[i] {
+				qs[i], specs[i] = q, spec
+			}
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for i, offer := range offers {
+		if qs[i] > bestQ {
+			best, bestQ = offer, qs[i]
+		}
+	}
+	return best
+}
+
+// matchMedia степень совпадения диапазона из Accept с типом: 2 точное, 1 type/*, 0 */*, -1 нет
+func matchMedia(rng, offer string) int {
+	if rng == offer {
+		return 2
+	}
+	if rng == "*/*" {
+		return 0
+	}
+	if t, ok := strings.CutSuffix(rng, "/*"); ok && strings.HasPrefix(offer, t+"/") {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,60 @@
+package httpx
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"text/csv", "application/x-ndjson"}
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{name: "empty takes first offer", accept: "", want: "text/csv"},
+		{name: "blank takes first offer", accept: "  ", want: "text/csv"},
+		{name: "exact", accept: "application/x-ndjson", want: "application/x-ndjson"},
+		{name: "any", accept: "*/*", want: "text/csv"},
+		{name: "type wildcard", accept: "application/*", want: "application/x-ndjson"},
+		{name: "higher q wins", accept: "text/csv;q=0.5, application/x-ndjson;q=0.9", want: "application/x-ndjson"},
+		{name: "equal q keeps offer order", accept: "application/x-ndjson, text/csv", want: "text/csv"},
+		{name: "q=0 excludes", accept: "text/csv;q=0, */*", want: "application/x-ndjson"},
+		{name: "q=0 wildcard excludes all", accept: "*/*;q=0", want: ""},
+		{name: "specific range overrides wildcard", accept: "*/*;q=0.1, text/csv;q=0", want: "application/x-ndjson"},
+		{name: "nothing matches", accept: "application/json", want: ""},
+		{name: "bad q skipped", accept: "text/csv;q=abc, application/x-ndjson;q=0.2", want: "application/x-ndjson"},
+		{name: "malformed range skipped", accept: "/;;, text/csv", want: "text/csv"},
+		{name: "params ignored", accept: "text/csv; charset=utf-8", want: "text/csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			if got := Negotiate(r, offers...); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchMedia(t *testing.T) {
+	tests := []struct {
+		rng, offer string
+		want       int
+	}{
+		{"text/csv", "text/csv", 2},
+		{"text/*", "text/csv", 1},
+		{"*/*", "text/csv", 0},
+		{"application/*", "text/csv", -1},
+		{"text/plain", "text/csv", -1},
+		{"tex/*", "text/csv", -1},
+	}
+	for _, tt := range tests {
+		if got := matchMedia(tt.rng, tt.offer); got != tt.want {
+			t.Errorf("matchMedia(%q, %q) = %d, want %d", tt.rng, tt.offer, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// ProblemContentType тип ответа об ошибке по RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix type у Problem — URN с кодом ошибки
+const problemTypePrefix = "urn:problem:"
+
+// Problem ответ об ошибке по RFC 7807
+// Code — стабильный код из каталога, Instance — request ID, Errors заполняется для ошибок валидации
+type Problem struct {
+	Type     string       `json:"type" example:"urn:problem:validation_failed"`
+	Title    string       `json:"title" example:"Bad Request"`
+	Status   int          `json:"status" example:"400"`
+	Detail   string       `json:"detail,omitempty" example:"price must be > 0"`
+	Instance string       `json:"instance,omitempty" example:"host/abcdef-000001"`
+	Code     string       `json:"code" example:"validation_failed"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError ошибка одного поля запроса
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Code    string `json:"code" example:"out_of_range" enums:"required,invalid_format,invalid_value,out_of_range"`
+	Message string `json:"message" example:"price must be > 0"`
+}
+
+// Коды ошибок API, клиенты завязываются на них, поэтому значения не меняем
+const (
+	CodeBadRequest           = "bad_request"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeValidationFailed     = "validation_failed"
+	CodeNotFound             = "not_found"
+	CodeRateNotFound         = "rate_not_found"
+	CodeInvalidDates         = "invalid_dates"
+	CodeInvalidPrice         = "invalid_price"
+	CodeInvalidBillingPeriod = "invalid_billing_period"
+	CodeInvalidPriceChange   = "invalid_price_change"
+	CodeInvalidPause         = "invalid_pause"
+	CodePauseOverlap         = "pause_overlap"
+	CodePauseNotFound        = "pause_not_found"
+	CodeNotPaused            = "not_paused"
+	CodeInvalidCurrency      = "invalid_currency"
+	CodeInvalidCursor        = "invalid_cursor"
+	CodeInvalidRate          = "invalid_rate"
+	CodeNoExchangeRate       = "no_exchange_rate"
+	CodeInvalidInput         = "invalid_input"
+	CodeAlreadyExists        = "already_exists"
+	CodeReferenceNotFound    = "reference_not_found"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeIdempotencyReused    = "idempotency_key_reused"
+	CodeIdempotencyInFlight  = "idempotency_in_progress"
+	CodeNotAcceptable        = "not_acceptable"
+	CodeUnsupportedMedia     = "unsupported_media_type"
+	CodeTooLarge             = "request_too_large"
+	CodeInternal             = "internal_error"
+	CodeUnavailable          = "service_unavailable"
+	CodeTimeout              = "timeout"
+)
+
+// catalogue доменные ошибки и их статус с кодом, проверяются по порядку через errors.Is
+var catalogue = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{domain.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{domain.ErrRateNotFound, http.StatusNotFound, CodeRateNotFound},
+	{domain.ErrPauseNotFound, http.StatusNotFound, CodePauseNotFound},
+	{domain.ErrInvalidDates, http.StatusBadRequest, CodeInvalidDates},
+	{domain.ErrInvalidPrice, http.StatusBadRequest, CodeInvalidPrice},
+	{domain.ErrInvalidBillingPeriod, http.StatusBadRequest, CodeInvalidBillingPeriod},
+	{domain.ErrInvalidPriceChange, http.StatusBadRequest, CodeInvalidPriceChange},
+	{domain.ErrInvalidPause, http.StatusBadRequest, CodeInvalidPause},
+	{domain.ErrPauseOverlap, http.StatusConflict, CodePauseOverlap},
+	{domain.ErrNotPaused, http.StatusConflict, CodeNotPaused},
+	{domain.ErrInvalidCurrency, http.StatusBadRequest, CodeInvalidCurrency},
+	{domain.ErrInvalidCursor, http.StatusBadRequest, CodeInvalidCursor},
+	{domain.ErrInvalidRate, http.StatusBadRequest, CodeInvalidRate},
+	{domain.ErrNoExchangeRate, http.StatusBadRequest, CodeNoExchangeRate},
+	{domain.ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput},
+	{domain.ErrAlreadyExists, http.StatusConflict, CodeAlreadyExists},
+	{domain.ErrReferenceNotFound, http.StatusConflict, CodeReferenceNotFound},
+	{domain.ErrConflict, http.StatusPreconditionFailed, CodePreconditionFailed},
+	{domain.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, CodeIdempotencyReused},
+	{domain.ErrIdempotencyInProgress, http.StatusConflict, CodeIdempotencyInFlight},
+	{domain.ErrUnavailable, http.StatusServiceUnavailable, CodeUnavailable},
+	{domain.ErrTimeout, http.StatusServiceUnavailable, CodeTimeout},
+	{context.DeadlineExceeded, http.StatusServiceUnavailable, CodeUnavailable},
+	{context.Canceled, http.StatusServiceUnavailable, CodeUnavailable},
+}
+
+// statusCodes коды для ошибок, статус которых задаёт сам хендлер
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotAcceptable:         CodeNotAcceptable,
+	http.StatusPreconditionFailed:    CodePreconditionFailed,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMedia,
+	http.StatusInternalServerError:   CodeInternal,
+}
+
+// Classify статус и код ответа для ошибки сервиса
+// Ошибки валидации и доменные — 4xx, всё остальное считаем сбоем инфраструктуры
+func Classify(err error) (int, string) {
+	var ve *domain.ValidationError
+	if errors.As(err, &ve) {
+		return http.StatusBadRequest, CodeValidationFailed
+	}
+	for _, c := range catalogue {
+		if errors.Is(err, c.err) {
+			return c.status, c.code
+		}
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
+
+// Error отвечаем problem+json по ошибке сервиса, статус и код берём из каталога
+// 5xx пишем в лог вместе с request ID, текст неизвестных ошибок клиенту не отдаём
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	status, code := Classify(err)
+	p := newProblem(r, status, code, err)
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed",
+			"err", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"req_id", p.Instance,
+		)
+	}
+	if code == CodeInternal {
+		p.Detail = http.StatusText(status)
+	}
+	// Временная недоступность, клиент может повторить запрос
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	WriteProblem(w, p)
+}
+
+// ErrorStatus отвечаем problem+json с явным статусом, для ошибок разбора запроса в хендлере
+func ErrorStatus(w http.ResponseWriter, r *http.Request, status int, err error) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = CodeBadRequest
+	}
+	WriteProblem(w, newProblem(r, status, code, err))
+}
+
+// WriteProblem пишем Problem с его статусом
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// newProblem собираем Problem, для domain.ValidationError добавляем список ошибок по полям
+func newProblem(r *http.Request, status int, code string, err error) Problem {
+	p := Problem{
+		Type:     problemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: chimw.GetReqID(r.Context()),
+		Code:     code,
+	}
+	var ve *domain.ValidationError
+	if errors.As(err, &ve) {
+		p.Errors = make([]FieldError, 0, len(ve.Fields))
+		for _, f := range ve.Fields {
+			p.Errors = append(p.Errors, FieldError{Field: f.Field, Code: f.Code, Message: f.Message})
+		}
+	}
+	return p
+}
@@ -0,0 +1,113 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{name: "validation", err: domain.Invalid("price", domain.CodeOutOfRange, "price must be > 0"),
+			status: http.StatusBadRequest, code: CodeValidationFailed},
+		{name: "validation wraps domain error", err: func() error {
+			var v domain.ValidationError
+			v.AddErr("end_date", domain.CodeOutOfRange, domain.ErrInvalidDates)
+			return v.Err()
+		}(), status: http.StatusBadRequest, code: CodeValidationFailed},
+		{name: "not found", err: domain.ErrNotFound, status: http.StatusNotFound, code: CodeNotFound},
+		{name: "wrapped", err: fmt.Errorf("get: %w", domain.ErrPauseNotFound), status: http.StatusNotFound, code: CodePauseNotFound},
+		{name: "conflict", err: domain.ErrConflict, status: http.StatusPreconditionFailed, code: CodePreconditionFailed},
+		{name: "in progress", err: fmt.Errorf("%w, retry in 5 s", domain.ErrIdempotencyInProgress),
+			status: http.StatusConflict, code: CodeIdempotencyInFlight},
+		{name: "unavailable", err: domain.ErrUnavailable, status: http.StatusServiceUnavailable, code: CodeUnavailable},
+		{name: "deadline", err: context.DeadlineExceeded, status: http.StatusServiceUnavailable, code: CodeUnavailable},
+		{name: "unknown", err: errors.New("boom"), status: http.StatusInternalServerError, code: CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := Classify(tt.err)
+			if status != tt.status || code != tt.code {
+				t.Errorf("Classify(%v) = %d, %s; want %d, %s", tt.err, status, code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+// Каждая ошибка каталога должна находиться своей строкой, а не более ранней
+func TestCatalogueOrder(t *testing.T) {
+	for _, c := range catalogue {
+		if status, code := Classify(c.err); status != c.status || code != c.code {
+			t.Errorf("Classify(%v) = %d, %s; want %d, %s", c.err, status, code, c.status, c.code)
+		}
+	}
+}
+
+func TestNewProblem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), chimw.RequestIDKey, "host/abcdef-000001"))
+
+	var v domain.ValidationError
+	v.Add("price", domain.CodeOutOfRange, "price must be > 0")
+	v.Add("start_date", domain.CodeRequired, "start_date is required")
+	p := newProblem(r, http.StatusBadRequest, CodeValidationFailed, v.Err())
+	want := Problem{
+		Type:     "urn:problem:validation_failed",
+		Title:    "Bad Request",
+		Status:   http.StatusBadRequest,
+		Detail:   "price must be > 0; start_date is required",
+		Instance: "host/abcdef-000001",
+		Code:     CodeValidationFailed,
+	}
+	if p.Type != want.Type || p.Title != want.Title || p.Status != want.Status || p.Detail != want.Detail ||
+		p.Instance != want.Instance || p.Code != want.Code {
+		t.Errorf("newProblem() = %+v, want %+v", p, want)
+	}
+	wantFields := []FieldError{
+		{Field: "price", Code: domain.CodeOutOfRange, Message: "price must be > 0"},
+		{Field: "start_date", Code: domain.CodeRequired, Message: "start_date is required"},
+	}
+	if len(p.Errors) != len(wantFields) {
+		t.Fatalf("errors = %+v, want %+v", p.Errors, wantFields)
+	}
+	for i := range wantFields {
+		if p.Errors[i] != wantFields[i] {
+			t.Errorf("errors[%d] = %+v, want %+v", i, p.Errors[i], wantFields[i])
+		}
+	}
+
+	p = newProblem(r, http.StatusNotFound, CodeNotFound, domain.ErrNotFound)
+	if p.Errors != nil || p.Detail != domain.ErrNotFound.Error() {
+		t.Errorf("non-validation problem = %+v", p)
+	}
+}
+
+func TestErrorHidesInternalDetail(t *testing.T) {
+	w := httptest.NewRecorder()
+	Error(w, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("pq: password authentication failed"))
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("status = %d, content type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"detail":"Internal Server Error"`) || strings.Contains(body, "password") {
+		t.Errorf("body = %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	Error(w, httptest.NewRequest(http.MethodGet, "/", nil), domain.ErrUnavailable)
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("503 without Retry-After: %v", w.Header())
+	}
+}
@@ -2,13 +2,20 @@ package httpx
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
-// ErrorResponse Формат ошибок для клиента
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+// MaxBodySize предел размера тела запроса, который читается целиком (импорт CSV, хеш Idempotency-Key)
+const MaxBodySize = 10 << 20
+
+// BodyStatus статус для ошибки чтения тела: 413 при превышении http.MaxBytesReader, иначе 400
+func BodyStatus(err error) int {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
 }
 
 // JSON Обертка для json
@@ -17,8 +24,3 @@ func JSON(w http.ResponseWriter, status int, v any) {
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(v)
 }
-
-// Error собираем ErrorResponse и вызываем JSON
-func Error(w http.ResponseWriter, status int, err error) {
-	JSON(w, status, ErrorResponse{Error: http.StatusText(status), Message: err.Error()})
-}
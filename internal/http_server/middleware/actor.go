@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// Actor кладёт в контекст идентификатор вызывающего для created_by/updated_by и request ID для журнала изменений
+// Аутентификации пока нет, поэтому вызывающий — это тоже request ID, ставить после RequestID
+func Actor() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := chimw.GetReqID(r.Context()); id != "" {
+				ctx := domain.WithRequestID(r.Context(), id)
+				r = r.WithContext(domain.WithActor(ctx, id))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
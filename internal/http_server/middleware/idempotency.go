@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/http_server/httpx"
+)
+
+// IdempotencyStore хранилище ответов по Idempotency-Key, реализовано в repo.PGRepo
+type IdempotencyStore interface {
+	ClaimIdempotencyKey(ctx context.Context, key, hash string, ttl, lease time.Duration) (*domain.IdempotencyRecord, bool, error)
+	SaveIdempotentResponse(ctx context.Context, rec *domain.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+// maxIdempotencyKeyLen ограничение длины ключа, обычно это UUID
+const maxIdempotencyKeyLen = 255
+
+// replayHeaders заголовки ответа, которые сохраняем и отдаём при повторе
+var replayHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Idempotency middleware для POST с заголовком Idempotency-Key
+// Первый ответ на ключ сохраняется на ttl и отдаётся повторам с тем же телом,
+// тот же ключ с другим запросом — 422, повтор во время выполнения первого — 409 с Retry-After:
+// ключ без ответа занят на lease, после этого тот же запрос выполнится заново
+// Ответы 5xx не сохраняем, такой запрос можно повторить с тем же ключом
+// Тело больше httpx.MaxBodySize — 413 до обращения к хранилищу
+func Idempotency(store IdempotencyStore, ttl, lease time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				httpx.Error(w, r, domain.Invalid("Idempotency-Key", domain.CodeOutOfRange,
+					fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLen)))
+				return
+			}
+
+			// Тело читаем целиком для хеша и отдаём хендлеру заново, не больше httpx.MaxBodySize
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, httpx.MaxBodySize))
+			if err != nil {
+				httpx.ErrorStatus(w, r, httpx.BodyStatus(err), err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			hash := requestHash(r, body)
+
+			rec, claimed, err := store.ClaimIdempotencyKey(r.Context(), key, hash, ttl, lease)
+			if err != nil {
+				httpx.Error(w, r, err)
+				return
+			}
+			if !claimed {
+				switch {
+				case rec.RequestHash != hash:
+					httpx.Error(w, r, domain.ErrIdempotencyKeyReused)
+				case !rec.Done():
+					wait := max(int(rec.RetryAfter.Round(time.Second)/time.Second), 1)
+					w.Header().Set("Retry-After", strconv.Itoa(wait))
+					httpx.Error(w, r, fmt.Errorf("%w, retry in %d s", domain.ErrIdempotencyInProgress, wait))
+				default:
+					replay(w, rec)
+				}
+				return
+			}
+
+			// Сохраняем и освобождаем ключ даже если клиент уже отключился
+			ctx := context.WithoutCancel(r.Context())
+			rw := &recorder{ResponseWriter: w, status: http.StatusOK}
+			saved := false
+			defer func() {
+				// Паника или 5xx: ответа нет, ключ освобождаем для повтора
+				if !saved {
+					if err := store.ReleaseIdempotencyKey(ctx, key); err != nil {
+						slog.ErrorContext(ctx, "idempotency: release key failed", "err", err, "key", key)
+					}
+				}
+			}()
+			next.ServeHTTP(rw, r)
+			if rw.status >= http.StatusInternalServerError {
+				return
+			}
+
+			rec = &domain.IdempotencyRecord{Key: key, RequestHash: hash, Status: rw.status, Header: map[string]string{}, Body: rw.body.Bytes()}
+			for _, h := range replayHeaders {
+				if v := w.Header().Get(h); v != "" {
+					rec.Header[h] = v
+				}
+			}
+			if err := store.SaveIdempotentResponse(ctx, rec); err != nil {
+				slog.ErrorContext(ctx, "idempotency: save response failed", "err", err, "key", key)
+				return
+			}
+			saved = true
+		})
+	}
+}
+
+// requestHash sha256 от метода, пути с query и тела
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay отдаём сохранённый ответ с пометкой Idempotent-Replayed
+func replay(w http.ResponseWriter, rec *domain.IdempotencyRecord) {
+	for h, v := range rec.Header {
+		w.Header().Set(h, v)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(rec.Status)
+	_, _ = w.Write(rec.Body)
+}
+
+// recorder пропускает ответ клиенту и запоминает статус и тело
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recorder) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status, rw.wroteHeader = status, true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recorder) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap для http.ResponseController
+func (rw *recorder) Unwrap() http.ResponseWriter { return rw.ResponseWriter }
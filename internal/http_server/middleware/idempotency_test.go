@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+)
+
+// memStore IdempotencyStore в памяти: ключ занят, пока запись есть
+type memStore struct {
+	recs     map[string]*domain.IdempotencyRecord
+	released []string
+	lease    time.Duration
+}
+
+func newMemStore() *memStore {
+	return &memStore{recs: map[string]*domain.IdempotencyRecord{}}
+}
+
+func (s *memStore) ClaimIdempotencyKey(_ context.Context, key, hash string, _, lease time.Duration) (*domain.IdempotencyRecord, bool, error) {
+	s.lease = lease
+	if rec, ok := s.recs[key]; ok {
+		return rec, false, nil
+	}
+	s.recs[key] = &domain.IdempotencyRecord{Key: key, RequestHash: hash}
+	return nil, true, nil
+}
+
+func (s *memStore) SaveIdempotentResponse(_ context.Context, rec *domain.IdempotencyRecord) error {
+	s.recs[rec.Key] = rec
+	return nil
+}
+
+func (s *memStore) ReleaseIdempotencyKey(_ context.Context, key string) error {
+	s.released = append(s.released, key)
+	delete(s.recs, key)
+	return nil
+}
+
+// counter хендлер, который считает вызовы и отвечает status с телом запроса
+type counter struct {
+	calls  int
+	status int
+}
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.calls++
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", `"1"`)
+	w.Header().Set("X-Other", "x")
+	w.WriteHeader(c.status)
+	_, _ = w.Write(body)
+}
+
+func post(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	const body = `{"service_name":"Yandex Plus"}`
+	tests := []struct {
+		name       string
+		status     int                                                           // ответ хендлера
+		prepare    func(s *memStore, h http.Handler)                             // состояние до проверяемого запроса
+		key, body  string                                                        // проверяемый запрос
+		wantStatus int                                                           // его статус
+		wantCalls  int                                                           // сколько раз всего вызван хендлер
+		check      func(t *testing.T, s *memStore, w *httptest.ResponseRecorder) // дополнительные проверки
+	}{
+		{
+			name: "no key passes through", status: http.StatusCreated,
+			prepare: func(_ *memStore, h http.Handler) { post(h, "", body) },
+			body:    body, wantStatus: http.StatusCreated, wantCalls: 2,
+			check: func(t *testing.T, s *memStore, _ *httptest.ResponseRecorder) {
+				if len(s.recs) != 0 {
+					t.Errorf("store used without key: %v", s.recs)
+				}
+			},
+		},
+		{
+			name: "first request saved", status: http.StatusCreated,
+			key: "k", body: body, wantStatus: http.StatusCreated, wantCalls: 1,
+			check: func(t *testing.T, s *memStore, _ *httptest.ResponseRecorder) {
+				rec := s.recs["k"]
+				if rec == nil || rec.Status != http.StatusCreated || string(rec.Body) != body {
+					t.Fatalf("saved record = %+v", rec)
+				}
+				if rec.Header["ETag"] != `"1"` || rec.Header["X-Other"] != "" {
+					t.Errorf("saved headers = %v, want only replay headers", rec.Header)
+				}
+				if s.lease != time.Minute {
+					t.Errorf("lease = %v, want %v", s.lease, time.Minute)
+				}
+			},
+		},
+		{
+			name: "repeat replayed", status: http.StatusCreated,
+			prepare: func(_ *memStore, h http.Handler) { post(h, "k", body) },
+			key:     "k", body: body, wantStatus: http.StatusCreated, wantCalls: 1,
+			check: func(t *testing.T, _ *memStore, w *httptest.ResponseRecorder) {
+				if w.Header().Get("Idempotent-Replayed") != "true" || w.Header().Get("ETag") != `"1"` || w.Body.String() != body {
+					t.Errorf("replay = %v %q", w.Header(), w.Body.String())
+				}
+			},
+		},
+		{
+			name: "4xx replayed too", status: http.StatusBadRequest,
+			prepare: func(_ *memStore, h http.Handler) { post(h, "k", body) },
+			key:     "k", body: body, wantStatus: http.StatusBadRequest, wantCalls: 1,
+		},
+		{
+			name: "other body rejected", status: http.StatusCreated,
+			prepare: func(_ *memStore, h http.Handler) { post(h, "k", body) },
+			key:     "k", body: `{"service_name":"Netflix"}`, wantStatus: http.StatusUnprocessableEntity, wantCalls: 1,
+		},
+		{
+			name: "in progress with retry after", status: http.StatusCreated,
+			prepare: func(s *memStore, _ http.Handler) {
+				s.recs["k"] = &domain.IdempotencyRecord{Key: "k", RequestHash: hashOf(body), RetryAfter: 90 * time.Second}
+			},
+			key: "k", body: body, wantStatus: http.StatusConflict,
+			check: func(t *testing.T, _ *memStore, w *httptest.ResponseRecorder) {
+				if got := w.Header().Get("Retry-After"); got != "90" {
+					t.Errorf("Retry-After = %q, want 90", got)
+				}
+				if !strings.Contains(w.Body.String(), "retry in 90 s") {
+					t.Errorf("body = %s, want wait time in detail", w.Body.String())
+				}
+			},
+		},
+		{
+			name: "in progress retry after at least a second", status: http.StatusCreated,
+			prepare: func(s *memStore, _ http.Handler) {
+				s.recs["k"] = &domain.IdempotencyRecord{Key: "k", RequestHash: hashOf(body)}
+			},
+			key: "k", body: body, wantStatus: http.StatusConflict,
+			check: func(t *testing.T, _ *memStore, w *httptest.ResponseRecorder) {
+				if got := w.Header().Get("Retry-After"); got != "1" {
+					t.Errorf("Retry-After = %q, want 1", got)
+				}
+			},
+		},
+		{
+			name: "5xx releases key", status: http.StatusInternalServerError,
+			prepare: func(_ *memStore, h http.Handler) { post(h, "k", body) },
+			key:     "k", body: body, wantStatus: http.StatusInternalServerError, wantCalls: 2,
+			check: func(t *testing.T, s *memStore, _ *httptest.ResponseRecorder) {
+				if len(s.released) != 2 || s.recs["k"] != nil {
+					t.Errorf("released = %v, records = %v", s.released, s.recs)
+				}
+			},
+		},
+		{
+			name: "key too long", status: http.StatusCreated,
+			key: strings.Repeat("k", maxIdempotencyKeyLen+1), body: body, wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "body too large", status: http.StatusCreated,
+			key: "k", body: strings.Repeat(" ", 10<<20+1), wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newMemStore()
+			c := &counter{status: tt.status}
+			h := Idempotency(s, time.Hour, time.Minute)(c)
+			if tt.prepare != nil {
+				tt.prepare(s, h)
+			}
+			w := post(h, tt.key, tt.body)
+			if w.Code != tt.wantStatus || c.calls != tt.wantCalls {
+				t.Fatalf("status = %d, calls = %d; want %d, %d (%s)", w.Code, c.calls, tt.wantStatus, tt.wantCalls, w.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, s, w)
+			}
+		})
+	}
+}
+
+func TestIdempotencyStoreError(t *testing.T) {
+	c := &counter{status: http.StatusCreated}
+	h := Idempotency(failingStore{newMemStore()}, time.Hour, time.Minute)(c)
+	if w := post(h, "k", "{}"); w.Code != http.StatusServiceUnavailable || c.calls != 0 {
+		t.Errorf("status = %d, calls = %d; want 503 without calling handler", w.Code, c.calls)
+	}
+}
+
+// failingStore хранилище недоступно
+type failingStore struct{ *memStore }
+
+func (failingStore) ClaimIdempotencyKey(context.Context, string, string, time.Duration, time.Duration) (*domain.IdempotencyRecord, bool, error) {
+	return nil, false, domain.ErrUnavailable
+}
+
+func hashOf(body string) string {
+	return requestHash(httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", nil), []byte(body))
+}
@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/http_server/httpx"
 )
 
 // Recovery middleware на паники и ошибки в хендлерах
@@ -13,14 +16,19 @@ func Recovery(log *slog.Logger) func(http.Handler) http.Handler {
 			// Сработает даже если внутри хендлера panic
 			defer func() {
 				// Ловим Panic и логируем ошибку и стек
-				if rec := recover(); rec != nil {
+				rec := recover()
+				// ErrAbortHandler обрывает соединение, например при ошибке посреди потоковой выдачи
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				if rec != nil {
 					log.Error("panic recovered",
 						"panic", rec,
 						"stack", string(debug.Stack()),
 						"method", r.Method,
 						"path", r.URL.Path,
 					)
-					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					httpx.ErrorStatus(w, r, http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
 				}
 			}()
 			next.ServeHTTP(w, r)
@@ -11,6 +11,8 @@ import (
 type Handlers struct {
 	Health *handlers.HealthHandler
 	Subs   *handlers.SubHandlers
+	Rates  *handlers.RateHandlers
+	Admin  *handlers.AdminHandlers
 }
 
 func New(d Handlers, mws ...func(http.Handler) http.Handler) *chi.Mux {
@@ -30,6 +32,20 @@ func New(d Handlers, mws ...func(http.Handler) http.Handler) *chi.Mux {
 		r.Route("/subscriptions", d.Subs.Routes)
 		// Ручка расчёта суммы
 		r.Get("/cost/total", d.Subs.TotalCost)
+		// Помесячная разбивка
+		r.Get("/cost/breakdown", d.Subs.CostBreakdown)
+		// Выгрузка разбивки в CSV/NDJSON
+		r.Get("/cost/breakdown/export", d.Subs.CostBreakdownExport)
+		// Прогноз расходов на ближайшие месяцы
+		r.Get("/cost/forecast", d.Subs.CostForecast)
+		// Сравнение двух периодов
+		r.Get("/cost/compare", d.Subs.CostCompare)
+		// Сводка расходов пользователя
+		r.Get("/users/{user_id}/summary", d.Subs.UserSummary)
+		// Курсы валют для пересчёта сумм
+		r.Route("/exchange-rates", d.Rates.Routes)
+		// Обслуживание данных: очистка мягко удалённых
+		r.Route("/admin", d.Admin.Routes)
 	})
 	return r
 }
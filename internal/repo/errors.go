@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Коды SQLSTATE, которые переводим в доменные ошибки
+const (
+	pgStringTooLong       = "22001" // string_data_right_truncation
+	pgNumericOutOfRange   = "22003" // numeric_value_out_of_range
+	pgDatetimeOverflow    = "22008" // datetime_field_overflow
+	pgInvalidText         = "22P02" // invalid_text_representation, например id не UUID
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+	pgSerialization       = "40001" // serialization_failure
+	pgDeadlock            = "40P01"
+	pgQueryCanceled       = "57014" // statement_timeout или отмена запроса
+)
+
+// checkErrors check-ограничения таблиц и соответствующие им доменные ошибки
+// Имена — те, что Postgres сгенерировал для ограничений из migrations/
+var checkErrors = map[string]error{
+	"subscriptions_check":                    domain.ErrInvalidDates,
+	"subscriptions_price_check":              domain.ErrInvalidPrice,
+	"subscriptions_billing_period_check":     domain.ErrInvalidBillingPeriod,
+	"subscriptions_currency_check":           domain.ErrInvalidCurrency,
+	"subscription_price_changes_price_check": domain.ErrInvalidPrice,
+	"exchange_rates_currency_check":          domain.ErrInvalidCurrency,
+	"exchange_rates_rate_check":              domain.ErrInvalidRate,
+}
+
+// foreignKeyErrors внешние ключи и доменные ошибки, когда строки, на которую они ссылаются, нет
+// Остальные нарушения внешних ключей — ErrReferenceNotFound
+var foreignKeyErrors = map[string]error{
+	"subscription_price_changes_subscription_id_fkey": domain.ErrNotFound,
+	"subscription_pauses_subscription_id_fkey":        domain.ErrNotFound,
+}
+
+// dbError доменная ошибка вместе с исходной ошибкой драйвера
+// Клиенту уходит текст доменной ошибки, исходная остаётся для errors.As и логов
+type dbError struct {
+	domain error
+	cause  error
+}
+
+func (e *dbError) Error() string   { return e.domain.Error() }
+func (e *dbError) Unwrap() []error { return []error{e.domain, e.cause} }
+
+// LogValue в логах видна и доменная ошибка, и текст драйвера
+func (e *dbError) LogValue() slog.Value {
+	return slog.StringValue(e.domain.Error() + ": " + e.cause.Error())
+}
+
+// mapErr переводим ошибки pgx в доменные, остальные возвращаем как есть
+func mapErr(err error) error {
+	var de *dbError
+	if err == nil || errors.As(err, &de) {
+		return err
+	}
+	if d := classify(err); d != nil {
+		return &dbError{domain: d, cause: err}
+	}
+	return err
+}
+
+// classify доменная ошибка для ошибки драйвера или nil, если перевода нет
+func classify(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgInvalidText, pgStringTooLong, pgNumericOutOfRange, pgDatetimeOverflow, pgNotNullViolation:
+			return domain.ErrInvalidInput
+		case pgCheckViolation:
+			if d, ok := checkErrors[pgErr.ConstraintName]; ok {
+				return d
+			}
+			return domain.ErrInvalidInput
+		case pgUniqueViolation:
+			return domain.ErrAlreadyExists
+		case pgForeignKeyViolation:
+			if d, ok := foreignKeyErrors[pgErr.ConstraintName]; ok {
+				return d
+			}
+			return domain.ErrReferenceNotFound
+		case pgSerialization, pgDeadlock:
+			return domain.ErrUnavailable
+		case pgQueryCanceled:
+			return domain.ErrTimeout
+		}
+		// 08 — ошибки соединения, 53 — нехватка ресурсов, 57P — сервер останавливается
+		if strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "53") || strings.HasPrefix(pgErr.Code, "57P") {
+			return domain.ErrUnavailable
+		}
+		return nil
+	}
+	// Клиент отключился — не ошибка БД, отдаём как есть
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if pgconn.Timeout(err) {
+		return domain.ErrTimeout
+	}
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) {
+		return domain.ErrUnavailable
+	}
+	return nil
+}
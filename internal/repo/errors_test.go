@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClassify(t *testing.T) {
+	pg := func(code, constraint string) error {
+		return &pgconn.PgError{Code: code, ConstraintName: constraint, Message: "driver text"}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want error // nil — ошибка не переводится
+	}{
+		{name: "not uuid", err: pg(pgInvalidText, ""), want: domain.ErrInvalidInput},
+		{name: "too long", err: pg(pgStringTooLong, ""), want: domain.ErrInvalidInput},
+		{name: "not null", err: pg(pgNotNullViolation, ""), want: domain.ErrInvalidInput},
+		{name: "known check", err: pg(pgCheckViolation, "subscriptions_price_check"), want: domain.ErrInvalidPrice},
+		{name: "dates check", err: pg(pgCheckViolation, "subscriptions_check"), want: domain.ErrInvalidDates},
+		{name: "unknown check", err: pg(pgCheckViolation, "other_check"), want: domain.ErrInvalidInput},
+		{name: "unique", err: pg(pgUniqueViolation, ""), want: domain.ErrAlreadyExists},
+		{name: "price change fk", err: pg(pgForeignKeyViolation, "subscription_price_changes_subscription_id_fkey"), want: domain.ErrNotFound},
+		{name: "pause fk", err: pg(pgForeignKeyViolation, "subscription_pauses_subscription_id_fkey"), want: domain.ErrNotFound},
+		{name: "unknown fk", err: pg(pgForeignKeyViolation, "other_fkey"), want: domain.ErrReferenceNotFound},
+		{name: "serialization", err: pg(pgSerialization, ""), want: domain.ErrUnavailable},
+		{name: "deadlock", err: pg(pgDeadlock, ""), want: domain.ErrUnavailable},
+		{name: "statement timeout", err: pg(pgQueryCanceled, ""), want: domain.ErrTimeout},
+		{name: "connection lost", err: pg("08006", ""), want: domain.ErrUnavailable},
+		{name: "too many connections", err: pg("53300", ""), want: domain.ErrUnavailable},
+		{name: "admin shutdown", err: pg("57P01", ""), want: domain.ErrUnavailable},
+		{name: "syntax error", err: pg("42601", "")},
+		{name: "wrapped pg error", err: fmt.Errorf("insert: %w", pg(pgUniqueViolation, "")), want: domain.ErrAlreadyExists},
+		{name: "client canceled", err: context.Canceled},
+		{name: "connect error", err: &pgconn.ConnectError{}, want: domain.ErrUnavailable},
+		{name: "no rows", err: pgx.ErrNoRows},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classify(tt.err); got != tt.want {
+				t.Errorf("classify(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapErr(t *testing.T) {
+	if mapErr(nil) != nil {
+		t.Fatal("mapErr(nil) != nil")
+	}
+
+	cause := &pgconn.PgError{Code: pgUniqueViolation, Message: "duplicate key value"}
+	err := mapErr(cause)
+	if !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("mapErr() = %v, want ErrAlreadyExists", err)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr != cause {
+		t.Errorf("driver error lost: %v", err)
+	}
+	if err.Error() != domain.ErrAlreadyExists.Error() {
+		t.Errorf("Error() = %q, driver text must not leak", err.Error())
+	}
+	if mapErr(err) != err {
+		t.Error("mapErr wraps an already mapped error again")
+	}
+
+	plain := errors.New("boom")
+	if mapErr(plain) != plain {
+		t.Error("untranslated error must be returned as is")
+	}
+}
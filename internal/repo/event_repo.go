@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+)
+
+// ListEvents Журнал изменений подписки в порядке записи, есть и для удалённых подписок
+func (r *PGRepo) ListEvents(ctx context.Context, subscriptionID string) ([]domain.SubscriptionEvent, error) {
+	const q = `
+select id, subscription_id, action, before, after, actor, request_id, created_at
+from subscription_events
+where subscription_id = $1
+order by id;`
+
+	rows, err := r.db.Query(ctx, q, subscriptionID)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	defer rows.Close()
+	res := make([]domain.SubscriptionEvent, 0, 4)
+	for rows.Next() {
+		var e domain.SubscriptionEvent
+		if err := rows.Scan(&e.ID, &e.SubscriptionID, &e.Action, &e.Before, &e.After, &e.Actor, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, mapErr(err)
+		}
+		res = append(res, e)
+	}
+	return res, mapErr(rows.Err())
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// exportFetchSize сколько строк забираем из курсора за один FETCH
+const exportFetchSize = 500
+
+// ExportSubscriptions Все подписки по фильтрам списка без пагинации, по одной в fn
+// Строки читаем серверным курсором, поэтому память не растёт с размером выборки
+func (r *PGRepo) ExportSubscriptions(ctx context.Context, f ListFilter, fn func(*domain.Subscription) error) error {
+	q := `select ` + subColumns + ` from subscriptions` + listWhere + `
+` + listOrder(f.Sort)
+
+	return r.streamCursor(ctx, q, listArgs(f), func(rows pgx.Rows) error {
+		var s domain.Subscription
+		if err := scanSub(rows, &s); err != nil {
+			return err
+		}
+		return fn(&s)
+	})
+}
+
+// ExportBreakdown Помесячная разбивка как в CalcBreakdown, по строке в fn
+func (r *PGRepo) ExportBreakdown(ctx context.Context, f CostFilter, g BreakdownGroup, fn func(*domain.MonthlyCost) error) error {
+	return r.streamCursor(ctx, breakdownQuery, breakdownArgs(f, g), func(rows pgx.Rows) error {
+		c, err := scanMonthlyCost(rows)
+		if err != nil {
+			return err
+		}
+		return fn(&c)
+	})
+}
+
+// streamCursor открываем курсор на q в read-only транзакции и читаем его пачками по exportFetchSize
+// Ошибка из row прерывает чтение, курсор закрывается вместе с транзакцией
+func (r *PGRepo) streamCursor(ctx context.Context, q string, args []any, row func(pgx.Rows) error) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return mapErr(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `declare export_cur no scroll cursor for `+q, args...); err != nil {
+		return mapErr(err)
+	}
+	fetch := `fetch forward ` + strconv.Itoa(exportFetchSize) + ` from export_cur`
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return mapErr(err)
+		}
+		n := 0
+		for rows.Next() {
+			n++
+			if err := row(rows); err != nil {
+				rows.Close()
+				return mapErr(err)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return mapErr(err)
+		}
+		if n < exportFetchSize {
+			return tx.Commit(ctx)
+		}
+	}
+}
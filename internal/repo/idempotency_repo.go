@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// IdempotencyRepository хранение ответов по Idempotency-Key
+type IdempotencyRepository interface {
+	ClaimIdempotencyKey(ctx context.Context, key, hash string, ttl, lease time.Duration) (*domain.IdempotencyRecord, bool, error)
+	SaveIdempotentResponse(ctx context.Context, rec *domain.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	PurgeIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// ClaimIdempotencyKey Занимаем ключ на ttl, просроченный ключ занимаем заново
+// Ключ без ответа занят на lease: если первый запрос не ответил за это время (упал процесс),
+// тот же запрос может занять ключ снова. Если ключ уже занят, возвращаем его запись и false
+func (r *PGRepo) ClaimIdempotencyKey(ctx context.Context, key, hash string, ttl, lease time.Duration) (*domain.IdempotencyRecord, bool, error) {
+	const claim = `
+insert into idempotency_keys(key, request_hash, expires_at)
+values ($1, $2, now() + make_interval(secs => $3))
+on conflict (key) do update
+set request_hash=excluded.request_hash, status=null, headers=null, body=null,
+    created_at=now(), expires_at=excluded.expires_at
+where idempotency_keys.expires_at <= now()
+   or (idempotency_keys.status is null and idempotency_keys.request_hash = excluded.request_hash
+       and idempotency_keys.created_at <= now() - make_interval(secs => $4))
+returning key`
+	const get = `
+select request_hash, coalesce(status, 0), headers, body,
+       greatest(ceil(extract(epoch from created_at + make_interval(secs => $2) - now())), 1)::bigint
+from idempotency_keys where key=$1 and expires_at > now()`
+
+	// Вторая попытка нужна, если ключ истёк между insert и select
+	for range 2 {
+		var k string
+		err := r.db.QueryRow(ctx, claim, key, hash, ttl.Seconds(), lease.Seconds()).Scan(&k)
+		if err == nil {
+			return nil, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, mapErr(err)
+		}
+
+		rec := &domain.IdempotencyRecord{Key: key}
+		var wait int64
+		err = r.db.QueryRow(ctx, get, key, lease.Seconds()).Scan(&rec.RequestHash, &rec.Status, &rec.Header, &rec.Body, &wait)
+		if err == nil {
+			rec.RetryAfter = time.Duration(wait) * time.Second
+			return rec, false, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, mapErr(err)
+		}
+	}
+	return nil, false, domain.ErrIdempotencyInProgress
+}
+
+// SaveIdempotentResponse Сохраняем ответ на занятый ключ
+func (r *PGRepo) SaveIdempotentResponse(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	const q = `update idempotency_keys set status=$2, headers=$3, body=$4 where key=$1`
+	_, err := r.db.Exec(ctx, q, rec.Key, rec.Status, rec.Header, rec.Body)
+	return mapErr(err)
+}
+
+// ReleaseIdempotencyKey Освобождаем ключ без ответа, чтобы повтор выполнил запрос заново
+func (r *PGRepo) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	_, err := r.db.Exec(ctx, `delete from idempotency_keys where key=$1 and status is null`, key)
+	return mapErr(err)
+}
+
+// PurgeIdempotencyKeys Удаляем просроченные ключи, возвращаем их число
+func (r *PGRepo) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	ct, err := r.db.Exec(ctx, `delete from idempotency_keys where expires_at <= now()`)
+	if err != nil {
+		return 0, mapErr(err)
+	}
+	return ct.RowsAffected(), nil
+}
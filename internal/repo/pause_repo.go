@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreatePause Сохраняем паузу, если она не пересекается с другими паузами подписки
+// Строку подписки блокируем, чтобы параллельные паузы не разошлись с проверкой
+// Пауза меняет списания, поэтому в той же транзакции пишем pause в журнал и повышаем версию подписки
+func (r *PGRepo) CreatePause(ctx context.Context, p *domain.Pause) (*domain.Pause, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockSubscription(ctx, tx, p.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	// пауза без to_month длится бесконечно
+	const q = `
+with ins as (
+  insert into subscription_pauses(subscription_id, from_month, to_month)
+  select $1, $2, $3
+  where not exists (
+    select 1 from subscription_pauses
+    where subscription_id = $1
+      and from_month <= coalesce($3::date, 'infinity'::date)
+      and coalesce(to_month, 'infinity'::date) >= $2::date)
+  returning *
+), ev as (
+  insert into subscription_events(subscription_id, action, before, after, actor, request_id)
+  select subscription_id, '` + string(domain.EventPause) + `', null, to_jsonb(ins), $4, $5 from ins
+)
+select id, subscription_id, from_month, to_month from ins`
+
+	out := new(domain.Pause)
+	err = tx.QueryRow(ctx, q, p.SubscriptionID, p.From, p.To, domain.ActorFrom(ctx), domain.RequestIDFrom(ctx)).
+		Scan(&out.ID, &out.SubscriptionID, &out.From, &out.To)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPauseOverlap
+	}
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	if err := bumpVersion(ctx, tx, p.SubscriptionID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, mapErr(err)
+	}
+	return out, nil
+}
+
+// ResumePause Возобновляем подписку с месяца month: пауза, в которую он попадает, заканчивается месяцем раньше,
+// а пауза, начинающаяся ровно с month, удаляется целиком. Будущие паузы не трогаем, их отменяет DeletePause
+// resume пишем в журнал и повышаем версию подписки в той же транзакции
+func (r *PGRepo) ResumePause(ctx context.Context, subscriptionID string, month time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return mapErr(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockSubscription(ctx, tx, subscriptionID); err != nil {
+		return err
+	}
+
+	const find = `
+select id, from_month from subscription_pauses
+where subscription_id = $1 and from_month <= $2 and (to_month is null or to_month >= $2)`
+
+	var id string
+	var from time.Time
+	err = tx.QueryRow(ctx, find, subscriptionID, month).Scan(&id, &from)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrNotPaused
+	}
+	if err != nil {
+		return mapErr(err)
+	}
+	if from.Equal(month) {
+		err = deletePause(ctx, tx, id, domain.EventResume)
+	} else {
+		const q = `
+with old as (
+  select * from subscription_pauses where id = $1
+), upd as (
+  update subscription_pauses set to_month = $2::date - interval '1 month' where id = $1
+  returning *
+)
+insert into subscription_events(subscription_id, action, before, after, actor, request_id)
+select upd.subscription_id, '` + string(domain.EventResume) + `', to_jsonb(old), to_jsonb(upd), $3, $4 from upd, old`
+		_, err = tx.Exec(ctx, q, id, month, domain.ActorFrom(ctx), domain.RequestIDFrom(ctx))
+		err = mapErr(err)
+	}
+	if err != nil {
+		return err
+	}
+	if err := bumpVersion(ctx, tx, subscriptionID); err != nil {
+		return err
+	}
+	return mapErr(tx.Commit(ctx))
+}
+
+// DeletePause Отменяем паузу целиком, в том числе ещё не начавшуюся
+// Пишем pause_delete в журнал и повышаем версию подписки в той же транзакции
+func (r *PGRepo) DeletePause(ctx context.Context, subscriptionID, pauseID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return mapErr(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockSubscription(ctx, tx, subscriptionID); err != nil {
+		return err
+	}
+	var ok bool
+	err = tx.QueryRow(ctx, `select true from subscription_pauses where id = $1 and subscription_id = $2`, pauseID, subscriptionID).Scan(&ok)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrPauseNotFound
+	}
+	if err != nil {
+		return mapErr(err)
+	}
+	if err := deletePause(ctx, tx, pauseID, domain.EventPauseDelete); err != nil {
+		return err
+	}
+	if err := bumpVersion(ctx, tx, subscriptionID); err != nil {
+		return err
+	}
+	return mapErr(tx.Commit(ctx))
+}
+
+// deletePause удаляем паузу и пишем action в журнал, after пустой
+func deletePause(ctx context.Context, tx pgx.Tx, id string, action domain.EventAction) error {
+	const q = `
+with del as (
+  delete from subscription_pauses where id = $1
+  returning *
+)
+insert into subscription_events(subscription_id, action, before, after, actor, request_id)
+select subscription_id, $2, to_jsonb(del), null, $3, $4 from del`
+	_, err := tx.Exec(ctx, q, id, string(action), domain.ActorFrom(ctx), domain.RequestIDFrom(ctx))
+	return mapErr(err)
+}
+
+// bumpVersion паузы и смены цены меняют сумму списаний, поэтому меняем версию и ETag подписки
+func bumpVersion(ctx context.Context, tx pgx.Tx, id string) error {
+	_, err := tx.Exec(ctx, `update subscriptions set version = version + 1, updated_at = now(), updated_by = $2 where id = $1`,
+		id, domain.ActorFrom(ctx))
+	return mapErr(err)
+}
+
+// ListPauses Паузы подписки по возрастанию месяца начала
+func (r *PGRepo) ListPauses(ctx context.Context, subscriptionID string) ([]domain.Pause, error) {
+	const q = `
+select id, subscription_id, from_month, to_month
+from subscription_pauses
+where subscription_id = $1
+order by from_month;`
+
+	rows, err := r.db.Query(ctx, q, subscriptionID)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	defer rows.Close()
+	res := make([]domain.Pause, 0, 4)
+	for rows.Next() {
+		var p domain.Pause
+		if err := rows.Scan(&p.ID, &p.SubscriptionID, &p.From, &p.To); err != nil {
+			return nil, mapErr(err)
+		}
+		res = append(res, p)
+	}
+	return res, mapErr(rows.Err())
+}
+
+// lockSubscription блокируем строку подписки до конца транзакции, удалённую считаем отсутствующей
+func lockSubscription(ctx context.Context, tx pgx.Tx, id string) error {
+	var ok bool
+	err := tx.QueryRow(ctx, `select true from subscriptions where id = $1 and deleted_at is null for update`, id).Scan(&ok)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return mapErr(err)
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+)
+
+// SchedulePriceChange Сохраняем смену цены с месяца, смену на тот же месяц перезаписываем
+// Тем же запросом пишем price_change в журнал: before — перезаписанная смена, если была
+// Смена цены меняет списания, поэтому, как и пауза, идёт в транзакции с блокировкой подписки и повышает её версию
+func (r *PGRepo) SchedulePriceChange(ctx context.Context, p *domain.PriceChange) (*domain.PriceChange, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockSubscription(ctx, tx, p.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	const q = `
+with old as (
+  select * from subscription_price_changes
+  where subscription_id = $1 and effective_from = $2
+  for update
+), pc as (
+  insert into subscription_price_changes(subscription_id, effective_from, price)
+  values ($1,$2,$3)
+  on conflict (subscription_id, effective_from) do update set price = excluded.price
+  returning *
+), ev as (
+  insert into subscription_events(subscription_id, action, before, after, actor, request_id)
+  select pc.subscription_id, '` + string(domain.EventPriceChange) + `', (select to_jsonb(old) from old), to_jsonb(pc), $4, $5 from pc
+)
+select id, subscription_id, effective_from, price from pc`
+
+	out := new(domain.PriceChange)
+	err = tx.QueryRow(ctx, q, p.SubscriptionID, p.EffectiveFrom, p.Price, domain.ActorFrom(ctx), domain.RequestIDFrom(ctx)).
+		Scan(&out.ID, &out.SubscriptionID, &out.EffectiveFrom, &out.Price)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	if err := bumpVersion(ctx, tx, p.SubscriptionID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, mapErr(err)
+	}
+	return out, nil
+}
+
+// ListPriceChanges История смен цены подписки по возрастанию месяца
+func (r *PGRepo) ListPriceChanges(ctx context.Context, subscriptionID string) ([]domain.PriceChange, error) {
+	const q = `
+select id, subscription_id, effective_from, price
+from subscription_price_changes
+where subscription_id = $1
+order by effective_from;`
+
+	rows, err := r.db.Query(ctx, q, subscriptionID)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	defer rows.Close()
+	res := make([]domain.PriceChange, 0, 4)
+	for rows.Next() {
+		var p domain.PriceChange
+		if err := rows.Scan(&p.ID, &p.SubscriptionID, &p.EffectiveFrom, &p.Price); err != nil {
+			return nil, mapErr(err)
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+)
+
+// RateRepository курсы валют к рублю
+type RateRepository interface {
+	ListRates(ctx context.Context, currency *string) ([]domain.ExchangeRate, error)
+	UpsertRate(ctx context.Context, rt *domain.ExchangeRate) error
+	DeleteRate(ctx context.Context, rt *domain.ExchangeRate) error
+}
+
+// ListRates Читаем курсы, опционально по одной валюте
+func (r *PGRepo) ListRates(ctx context.Context, currency *string) ([]domain.ExchangeRate, error) {
+	const q = `
+select currency, month, rate::float8
+from exchange_rates
+where ($1::text is null or currency = $1::text)
+order by currency, month desc;`
+
+	rows, err := r.db.Query(ctx, q, currency)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	defer rows.Close()
+	res := make([]domain.ExchangeRate, 0, 16)
+	for rows.Next() {
+		var rt domain.ExchangeRate
+		if err := rows.Scan(&rt.Currency, &rt.Month, &rt.Rate); err != nil {
+			return nil, mapErr(err)
+		}
+		res = append(res, rt)
+	}
+	return res, rows.Err()
+}
+
+// UpsertRate Задаём курс валюты с месяца, существующий курс за этот месяц перезаписываем
+func (r *PGRepo) UpsertRate(ctx context.Context, rt *domain.ExchangeRate) error {
+	const q = `
+insert into exchange_rates(currency, month, rate)
+values ($1,$2,$3)
+on conflict (currency, month) do update set rate = excluded.rate`
+	_, err := r.db.Exec(ctx, q, rt.Currency, rt.Month, rt.Rate)
+	return mapErr(err)
+}
+
+// DeleteRate Удаляем курс за месяц, если строки нет, возвращаем ошибку
+func (r *PGRepo) DeleteRate(ctx context.Context, rt *domain.ExchangeRate) error {
+	ct, err := r.db.Exec(ctx, `delete from exchange_rates where currency=$1 and month=$2`, rt.Currency, rt.Month)
+	if err != nil {
+		return mapErr(err)
+	}
+	if ct.RowsAffected() == 0 {
+		return domain.ErrRateNotFound
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package repo
 import (
 	"context"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
@@ -11,24 +13,123 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// ListFilter фильтры и пагинация для списка.
+// ListFilter фильтры, сортировка и пагинация для списка.
+// After — keyset-позиция, при ней Offset игнорируется, работает только с сортировкой по умолчанию
 type ListFilter struct {
-	UserID      *string
-	ServiceName *string
-	Limit       int
-	Offset      int
+	UserID         *string
+	ServiceName    *string
+	PriceMin       *int
+	PriceMax       *int
+	ActiveAt       *time.Time // подписка активна в этом месяце
+	StartFrom      *time.Time // start_date >= StartFrom
+	StartTo        *time.Time // start_date <= StartTo
+	CreatedFrom    *time.Time // created_at >= CreatedFrom
+	CreatedTo      *time.Time // created_at < CreatedTo
+	UpdatedFrom    *time.Time // updated_at >= UpdatedFrom
+	UpdatedTo      *time.Time // updated_at < UpdatedTo
+	Status         *ListStatus
+	IncludeDeleted bool      // показывать и мягко удалённые
+	Sort           *ListSort // nil — start_date desc, id desc
+	Limit          int
+	Offset         int
+	After          *ListCursor
 }
 
-// SubscriptionRepository CRUD + сумма за период
+// ListStatus статус подписки относительно текущего месяца
+type ListStatus string
+
+const (
+	StatusActive    ListStatus = "active"     // идёт в текущем месяце
+	StatusEnded     ListStatus = "ended"      // закончилась до текущего месяца
+	StatusOpenEnded ListStatus = "open-ended" // без даты окончания
+)
+
+// ListSort сортировка списка, Field — одно из Sort* полей
+type ListSort struct {
+	Field string
+	Desc  bool
+}
+
+// Поля, по которым можно сортировать список
+const (
+	SortPrice       = "price"
+	SortStartDate   = "start_date"
+	SortEndDate     = "end_date"
+	SortServiceName = "service_name"
+	SortCreatedAt   = "created_at"
+	SortUpdatedAt   = "updated_at"
+)
+
+// ListCursor последняя запись предыдущей страницы в порядке start_date desc, id desc
+type ListCursor struct {
+	StartDate time.Time
+	ID        string
+}
+
+// SubscriptionPatch колонки для частичного обновления, nil — колонку не трогаем
+type SubscriptionPatch struct {
+	ServiceName   *string
+	Price         *int
+	IntroPhases   *[]domain.IntroPhase
+	Currency      *string
+	BillingPeriod *domain.BillingPeriod
+	UserID        *string
+	StartDate     *time.Time
+	DayPrecision  *bool      // точность дат, меняется вместе с end_date
+	SetEndDate    bool       // менять ли end_date
+	EndDate       *time.Time // при SetEndDate nil делает подписку бессрочной
+	Version       int        // ожидаемая версия записи, 0 — без проверки
+}
+
+// CostFilter период, фильтры и валюта для расчёта стоимости
+// From/To — первые числа месяцев, период включительно
+type CostFilter struct {
+	From           time.Time
+	To             time.Time
+	UserID         *string
+	ServiceName    *string
+	Currency       string          // валюта результата, ISO 4217
+	IncludeDeleted bool            // учитывать и мягко удалённые подписки
+	Rounding       domain.Rounding // округление сумм до рублей, пустое — half_up
+}
+
+// BreakdownGroup группировка помесячной разбивки, без флагов одна строка на месяц
+type BreakdownGroup struct {
+	ByService bool
+	ByUser    bool
+}
+
+// SubscriptionRepository CRUD + история цен + паузы + сумма за период
 type SubscriptionRepository interface {
 	Create(ctx context.Context, s *domain.Subscription) (*domain.Subscription, error)
-	Get(ctx context.Context, id string) (*domain.Subscription, error)
-	List(ctx context.Context, f ListFilter) ([]domain.Subscription, error)
+	CreateBatch(ctx context.Context, subs []*domain.Subscription) ([]domain.Subscription, error)
+	Get(ctx context.Context, id string, includeDeleted bool) (*domain.Subscription, error)
+	List(ctx context.Context, f ListFilter) ([]domain.Subscription, bool, error)
+	Count(ctx context.Context, f ListFilter) (int64, error)
 	Update(ctx context.Context, s *domain.Subscription) error
-	Delete(ctx context.Context, id string) error
-	CalcTotal(ctx context.Context, from, to time.Time, userID *string, serviceName *string) (int64, int, error)
+	Patch(ctx context.Context, id string, p SubscriptionPatch) (*domain.Subscription, error)
+	Delete(ctx context.Context, id string, version int) error
+	Restore(ctx context.Context, id string, version int) (*domain.Subscription, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	SchedulePriceChange(ctx context.Context, p *domain.PriceChange) (*domain.PriceChange, error)
+	ListPriceChanges(ctx context.Context, subscriptionID string) ([]domain.PriceChange, error)
+	ListEvents(ctx context.Context, subscriptionID string) ([]domain.SubscriptionEvent, error)
+	CreatePause(ctx context.Context, p *domain.Pause) (*domain.Pause, error)
+	ResumePause(ctx context.Context, subscriptionID string, month time.Time) error
+	DeletePause(ctx context.Context, subscriptionID, pauseID string) error
+	ListPauses(ctx context.Context, subscriptionID string) ([]domain.Pause, error)
+	CalcTotal(ctx context.Context, f CostFilter) (int64, int, error)
+	CalcBreakdown(ctx context.Context, f CostFilter, g BreakdownGroup) ([]domain.MonthlyCost, error)
+	CalcByService(ctx context.Context, f CostFilter) ([]domain.ServiceCost, error)
+	UserSummary(ctx context.Context, f CostFilter, today time.Time, upcoming int) (*domain.UserSummary, error)
+	ExportSubscriptions(ctx context.Context, f ListFilter, fn func(*domain.Subscription) error) error
+	ExportBreakdown(ctx context.Context, f CostFilter, g BreakdownGroup, fn func(*domain.MonthlyCost) error) error
 }
 
+// subColumns колонки подписки в порядке scanSub
+const subColumns = `id, service_name, price, currency, billing_period, user_id, start_date, end_date, version,
+created_at, updated_at, created_by, updated_by, deleted_at, day_precision, intro_phases`
+
 type PGRepo struct{ db *pgxpool.Pool }
 
 func NewPGRepo(db *pgxpool.Pool) *PGRepo { return &PGRepo{db: db} }
@@ -36,27 +137,81 @@ func NewPGRepo(db *pgxpool.Pool) *PGRepo { return &PGRepo{db: db} }
 // Create Вставляем запись и сразу возвращаем все нужные поля
 // Параметры передаются через плейсхолдеры
 func (r *PGRepo) Create(ctx context.Context, s *domain.Subscription) (*domain.Subscription, error) {
-	const q = `
-insert into subscriptions(service_name, price, user_id, start_date, end_date)
-values ($1,$2,$3,$4,$5)
-returning id, service_name, price, user_id, start_date, end_date`
-	row := r.db.QueryRow(ctx, q, s.ServiceName, s.Price, s.UserID, s.StartDate, s.EndDate)
+	row := r.db.QueryRow(ctx, insertSub, insertArgs(ctx, s)...)
 
 	// Создаем доменную модель для бизнес-логики
 	out := new(domain.Subscription)
 	// scanSub хелпер для Scan
 	if err := scanSub(row, out); err != nil {
-		return nil, err
+		return nil, mapErr(err)
+	}
+	return out, nil
+}
+
+// CreateBatch Вставляем все записи одной транзакцией через pgx.Batch (один round-trip)
+// Ошибка любой вставки откатывает всю пачку, результат в порядке входа
+func (r *PGRepo) CreateBatch(ctx context.Context, subs []*domain.Subscription) ([]domain.Subscription, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	// после Commit откат ничего не делает
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	b := &pgx.Batch{}
+	for _, s := range subs {
+		b.Queue(insertSub, insertArgs(ctx, s)...)
+	}
+	br := tx.SendBatch(ctx, b)
+	out := make([]domain.Subscription, len(subs))
+	for i := range subs {
+		if err := scanSub(br.QueryRow(), &out[i]); err != nil {
+			_ = br.Close()
+			return nil, mapErr(err)
+		}
+	}
+	if err := br.Close(); err != nil {
+		return nil, mapErr(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, mapErr(err)
 	}
 	return out, nil
 }
 
-// Get Читаем по id
-func (r *PGRepo) Get(ctx context.Context, id string) (*domain.Subscription, error) {
+// insertSub вставка подписки вместе с записью журнала, аргументы в порядке insertArgs
+// $9 — вызывающий из контекста, он же created_by и updated_by, $10 — request ID, $11 — вводные периоды
+const insertSub = `
+with ins as (
+  insert into subscriptions(service_name, price, currency, billing_period, user_id, start_date, end_date, day_precision,
+                            created_by, updated_by, intro_phases)
+  values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$9,$11)
+  returning *
+), ev as (
+  insert into subscription_events(subscription_id, action, after, actor, request_id)
+  select id, 'create', to_jsonb(ins), $9, $10 from ins
+)
+select ` + subColumns + ` from ins`
+
+func insertArgs(ctx context.Context, s *domain.Subscription) []any {
+	return []any{s.ServiceName, s.Price, s.Currency, s.BillingPeriod, s.UserID, s.StartDate, s.EndDate, s.DayPrecision,
+		domain.ActorFrom(ctx), domain.RequestIDFrom(ctx), introArg(s.IntroPhases)}
+}
+
+// introArg вводные периоды для колонки jsonb not null: nil пишем как []
+func introArg(ph []domain.IntroPhase) []domain.IntroPhase {
+	if ph == nil {
+		return []domain.IntroPhase{}
+	}
+	return ph
+}
+
+// Get Читаем по id, мягко удалённую — только с includeDeleted
+func (r *PGRepo) Get(ctx context.Context, id string, includeDeleted bool) (*domain.Subscription, error) {
 	const q = `
-select id, service_name, price, user_id, start_date, end_date from subscriptions where id=$1`
+select ` + subColumns + ` from subscriptions where id=$1 and ($2::bool or deleted_at is null)`
 
-	row := r.db.QueryRow(ctx, q, id)
+	row := r.db.QueryRow(ctx, q, id, includeDeleted)
 	// Создаем доменную модель для бизнес-логики
 	out := new(domain.Subscription)
 	// scanSub хелпер для Scan
@@ -65,96 +220,347 @@ select id, service_name, price, user_id, start_date, end_date from subscriptions
 			// маппим в ErrNotFound, чтобы HTTP-слой отдал ошибку
 			return nil, domain.ErrNotFound
 		}
-		return nil, err
+		return nil, mapErr(err)
 	}
 	return out, nil
 }
 
-func (r *PGRepo) List(ctx context.Context, f ListFilter) ([]domain.Subscription, error) {
-	limit := f.Limit
-	if limit <= 0 {
-		limit = 50
+// Normalized лимит по умолчанию 50 и не больше 200, offset не отрицательный и не используется с After
+func (f ListFilter) Normalized() ListFilter {
+	if f.Limit <= 0 {
+		f.Limit = 50
 	}
-	if limit > 200 { // чтобы не уронить БД случайным запросом
-		limit = 200
+	if f.Limit > 200 { // чтобы не уронить БД случайным запросом
+		f.Limit = 200
 	}
-	offset := f.Offset
-	if offset < 0 {
-		offset = 0
+	if f.Offset < 0 || f.After != nil {
+		f.Offset = 0
 	}
-	// service_name
+	return f
+}
+
+// currentPrice цена, действующая сейчас: последняя смена с effective_from не позже текущего месяца, иначе исходная
+// По ней фильтруем price_min/price_max и сортируем sort=price, вводные периоды не учитываются
+const currentPrice = `coalesce((
+    select pc.price from subscription_price_changes pc
+    where pc.subscription_id = subscriptions.id and pc.effective_from <= current_date
+    order by pc.effective_from desc limit 1), price)`
+
+// listWhere общие условия List и Count, параметры в порядке listArgs
+// $1 user_id
+// $2 service_name (ILIKE-шаблон)
+// $3/$4 диапазон текущей цены
+// $5 месяц, в котором подписка активна
+// $6/$7 диапазон start_date по месяцам, start_date с точностью до дня попадает в свой месяц
+// $8 статус относительно текущего месяца
+// $9/$10 диапазон created_at, $11/$12 диапазон updated_at (from включительно, to нет)
+// $13 показывать мягко удалённые
+const listWhere = `
+where ($1::uuid is null or user_id = $1::uuid)
+  and ($2::text is null or service_name ilike $2)
+  and ($3::int is null or ` + currentPrice + ` >= $3::int)
+  and ($4::int is null or ` + currentPrice + ` <= $4::int)
+  and ($5::date is null or (start_date < $5::date + interval '1 month' and (end_date is null or end_date >= $5::date)))
+  and ($6::date is null or start_date >= $6::date)
+  and ($7::date is null or start_date < $7::date + interval '1 month')
+  and ($8::text is null
+    or ($8::text = 'active' and start_date < date_trunc('month', current_date) + interval '1 month'
+        and (end_date is null or end_date >= date_trunc('month', current_date)))
+    or ($8::text = 'ended' and end_date < date_trunc('month', current_date))
+    or ($8::text = 'open-ended' and end_date is null))
+  and ($9::timestamptz is null or created_at >= $9::timestamptz)
+  and ($10::timestamptz is null or created_at < $10::timestamptz)
+  and ($11::timestamptz is null or updated_at >= $11::timestamptz)
+  and ($12::timestamptz is null or updated_at < $12::timestamptz)
+  and ($13::bool or deleted_at is null)`
+
+// Аргументы для listWhere
+func listArgs(f ListFilter) []any {
+	// service_name ищем по подстроке
 	var servName *string
 	if f.ServiceName != nil && *f.ServiceName != "" {
 		like := "%" + *f.ServiceName + "%"
 		servName = &like
 	}
+	return []any{f.UserID, servName, f.PriceMin, f.PriceMax, f.ActiveAt, f.StartFrom, f.StartTo, f.Status,
+		f.CreatedFrom, f.CreatedTo, f.UpdatedFrom, f.UpdatedTo, f.IncludeDeleted}
+}
 
-	const q = `
-select id, service_name, price, user_id, start_date, end_date
-from subscriptions
-where ($1::uuid is null or user_id = $1::uuid)
-  and ($2::text is null or service_name ilike $2)
-order by start_date desc, id desc
-limit $3 offset $4;`
+// Колонки сортировки, в SQL попадают только значения из этой карты
+var sortColumns = map[string]string{
+	SortPrice:       currentPrice,
+	SortStartDate:   "start_date",
+	SortEndDate:     "end_date",
+	SortServiceName: "service_name",
+	SortCreatedAt:   "created_at",
+	SortUpdatedAt:   "updated_at",
+}
 
-	rows, err := r.db.Query(ctx, q, f.UserID, servName, limit, offset)
+// listOrder order by для списка, id в конце даёт стабильный порядок между страницами
+func listOrder(s *ListSort) string {
+	if s == nil {
+		return "order by start_date desc, id desc"
+	}
+	col, ok := sortColumns[s.Field]
+	if !ok {
+		return "order by start_date desc, id desc"
+	}
+	if s.Desc {
+		return "order by " + col + " desc nulls last, id desc"
+	}
+	return "order by " + col + " asc nulls last, id asc"
+}
+
+// List Страница подписок, по умолчанию в порядке start_date desc, id desc
+// Режим keyset, если задан f.After, иначе limit/offset. hasMore — есть ли записи после страницы
+func (r *PGRepo) List(ctx context.Context, f ListFilter) ([]domain.Subscription, bool, error) {
+	f = f.Normalized()
+	// позиция keyset, NULL — с начала списка
+	var afterStart *time.Time
+	var afterID *string
+	if f.After != nil {
+		afterStart, afterID = &f.After.StartDate, &f.After.ID
+	}
+
+	// Берём на одну строку больше, чтобы узнать, есть ли следующая страница
+	q := `
+select ` + subColumns + `
+from subscriptions` + listWhere + `
+  and ($16::date is null or (start_date, id) < ($16::date, $17::uuid))
+` + listOrder(f.Sort) + `
+limit $14 + 1 offset $15;`
+
+	args := append(listArgs(f), f.Limit, f.Offset, afterStart, afterID)
+	rows, err := r.db.Query(ctx, q, args...)
 	if err != nil {
-		return nil, err
+		return nil, false, mapErr(err)
 	}
 	defer rows.Close()
 	// срез с capacity=16, чтобы уменьшить количество реаллокаций при небольшом ответе
 	res := make([]domain.Subscription, 0, 16)
 	for rows.Next() {
 		var s domain.Subscription
-		if err := rows.Scan(&s.ID, &s.ServiceName, &s.Price, &s.UserID, &s.StartDate, &s.EndDate); err != nil {
-			return nil, err
+		if err := scanSub(rows, &s); err != nil {
+			return nil, false, mapErr(err)
 		}
 		res = append(res, s)
 	}
-	return res, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, false, mapErr(err)
+	}
+	if len(res) > f.Limit {
+		return res[:f.Limit], true, nil
+	}
+	return res, false, nil
+}
+
+// Count Сколько всего подписок подходит под фильтры списка, пагинация не учитывается
+func (r *PGRepo) Count(ctx context.Context, f ListFilter) (int64, error) {
+	const q = `select count(*) from subscriptions` + listWhere
+	var n int64
+	err := r.db.QueryRow(ctx, q, listArgs(f)...).Scan(&n)
+	return n, mapErr(err)
 }
 
 // Update Полное обновление всех полей, если строка не найдена, возвращаем ошибку
+// s.Version — ожидаемая версия (0 — без проверки), после обновления s заполняем записью из БД
+// Запись журнала пишется тем же запросом
 func (r *PGRepo) Update(ctx context.Context, s *domain.Subscription) error {
 	const q = `
-update subscriptions
-set service_name=$2, price=$3, user_id=$4, start_date=$5, end_date=$6
-where id=$1`
-	ct, err := r.db.Exec(ctx, q, s.ID, s.ServiceName, s.Price, s.UserID, s.StartDate, s.EndDate)
-	if err != nil {
-		return err
+update subscriptions s
+set service_name=$2, price=$3, currency=$4, billing_period=$5, user_id=$6, start_date=$7, end_date=$8,
+    day_precision=$12, intro_phases=$13, version=s.version+1, updated_at=now(), updated_by=$10
+from old
+where s.id=old.id and s.deleted_at is null and ($9::int = 0 or s.version=$9)
+returning s.*`
+	err := scanSub(r.db.QueryRow(ctx, withEvent(q, domain.EventUpdate, "$10", "$11"), s.ID, s.ServiceName, s.Price, s.Currency, s.BillingPeriod, s.UserID,
+		s.StartDate, s.EndDate, s.Version, domain.ActorFrom(ctx), domain.RequestIDFrom(ctx), s.DayPrecision,
+		introArg(s.IntroPhases)), s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.notFoundOrConflict(ctx, s.ID, s.Version)
 	}
-	if ct.RowsAffected() == 0 {
-		return domain.ErrNotFound
+	return mapErr(err)
+}
+
+// Patch Обновляем только переданные колонки и возвращаем запись целиком
+// Пустой патч просто читает запись
+func (r *PGRepo) Patch(ctx context.Context, id string, p SubscriptionPatch) (*domain.Subscription, error) {
+	sets := make([]string, 0, 7)
+	args := []any{id}
+	set := func(col string, v any) {
+		args = append(args, v)
+		sets = append(sets, col+"=$"+strconv.Itoa(len(args)))
+	}
+	if p.ServiceName != nil {
+		set("service_name", *p.ServiceName)
+	}
+	if p.Price != nil {
+		set("price", *p.Price)
+	}
+	if p.IntroPhases != nil {
+		set("intro_phases", introArg(*p.IntroPhases))
+	}
+	if p.Currency != nil {
+		set("currency", *p.Currency)
+	}
+	if p.BillingPeriod != nil {
+		set("billing_period", *p.BillingPeriod)
+	}
+	if p.UserID != nil {
+		set("user_id", *p.UserID)
+	}
+	if p.StartDate != nil {
+		set("start_date", *p.StartDate)
+	}
+	if p.SetEndDate {
+		set("end_date", p.EndDate)
+	}
+	if p.DayPrecision != nil {
+		set("day_precision", *p.DayPrecision)
+	}
+	if len(sets) == 0 {
+		out, err := r.Get(ctx, id, false)
+		if err == nil && p.Version != 0 && out.Version != p.Version {
+			return nil, domain.ErrConflict
+		}
+		return out, err
+	}
+
+	set("updated_by", domain.ActorFrom(ctx))
+	actor := "$" + strconv.Itoa(len(args))
+	args = append(args, p.Version, domain.RequestIDFrom(ctx))
+	version, reqID := "$"+strconv.Itoa(len(args)-1), "$"+strconv.Itoa(len(args))
+	q := `
+update subscriptions s
+set ` + strings.Join(sets, ", ") + `, version=s.version+1, updated_at=now()
+from old
+where s.id=old.id and s.deleted_at is null and (` + version + `::int = 0 or s.version=` + version + `)
+returning s.*`
+	out := new(domain.Subscription)
+	if err := scanSub(r.db.QueryRow(ctx, withEvent(q, domain.EventUpdate, actor, reqID), args...), out); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, r.notFoundOrConflict(ctx, id, p.Version)
+		}
+		return nil, mapErr(err)
 	}
-	return nil
+	return out, nil
 }
 
-// Delete Удаляем по id, если строка не найдена, возвращаем ошибку
-func (r *PGRepo) Delete(ctx context.Context, id string) error {
-	ct, err := r.db.Exec(ctx, `delete from subscriptions where id=$1`, id)
+// Delete Мягкое удаление: ставим deleted_at, строка остаётся в расчётах с include_deleted
+// Удалённую повторно не удаляем, отдаём ErrNotFound
+// version — ожидаемая версия записи, 0 — без проверки. Запись журнала пишется тем же запросом
+func (r *PGRepo) Delete(ctx context.Context, id string, version int) error {
+	const q = `
+update subscriptions s
+set deleted_at=now(), version=s.version+1, updated_at=now(), updated_by=$3
+from old
+where s.id=old.id and s.deleted_at is null and ($2::int = 0 or s.version=$2)
+returning s.*`
+	var out domain.Subscription
+	err := scanSub(r.db.QueryRow(ctx, withEvent(q, domain.EventDelete, "$3", "$4"), id, version,
+		domain.ActorFrom(ctx), domain.RequestIDFrom(ctx)), &out)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.notFoundOrConflict(ctx, id, version)
+	}
+	return mapErr(err)
+}
+
+// Restore Снимаем мягкое удаление, неудалённую подписку отдаём как есть
+// version — ожидаемая версия записи, 0 — без проверки
+func (r *PGRepo) Restore(ctx context.Context, id string, version int) (*domain.Subscription, error) {
+	const q = `
+update subscriptions s
+set deleted_at=null, version=s.version+1, updated_at=now(), updated_by=$3
+from old
+where s.id=old.id and s.deleted_at is not null and ($2::int = 0 or s.version=$2)
+returning s.*`
+	out := new(domain.Subscription)
+	err := scanSub(r.db.QueryRow(ctx, withEvent(q, domain.EventRestore, "$3", "$4"), id, version,
+		domain.ActorFrom(ctx), domain.RequestIDFrom(ctx)), out)
+	if err == nil {
+		return out, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, mapErr(err)
+	}
+	// Не восстановили: записи нет, версия не совпала или подписка не удалена
+	cur, err := r.Get(ctx, id, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if ct.RowsAffected() == 0 {
-		return domain.ErrNotFound
+	if version != 0 && cur.Version != version {
+		return nil, domain.ErrConflict
 	}
-	return nil
+	return cur, nil
 }
 
-func (r *PGRepo) CalcTotal(ctx context.Context, from, to time.Time, userID *string, serviceName *string) (int64, int, error) {
-	// $1 user_id
-	// $2 service_name
-	// $3 from
-	// $4 to
-
+// PurgeDeleted Окончательно удаляем подписки, мягко удалённые раньше before, вместе с историей цен
+// В журнал пишем purge со строкой до удаления, сам журнал не чистим
+func (r *PGRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
 	const q = `
+with del as (
+  delete from subscriptions where deleted_at < $1
+  returning *
+), ev as (
+  insert into subscription_events(subscription_id, action, before, actor, request_id)
+  select id, 'purge', to_jsonb(del), $2, $3 from del
+)
+select count(*) from del`
+	var n int64
+	err := r.db.QueryRow(ctx, q, before, domain.ActorFrom(ctx), domain.RequestIDFrom(ctx)).Scan(&n)
+	return n, mapErr(err)
+}
+
+// withEvent оборачиваем update в запрос, который пишет запись журнала action с состоянием до и после
+// update должен ссылаться на old (текущая строка $1) и возвращать s.*, actor и reqID — номера параметров
+func withEvent(update string, action domain.EventAction, actor, reqID string) string {
+	return `
+with old as (
+  select * from subscriptions where id=$1 for update
+), upd as (` + update + `
+), ev as (
+  insert into subscription_events(subscription_id, action, before, after, actor, request_id)
+  select upd.id, '` + string(action) + `', to_jsonb(old), to_jsonb(upd), ` + actor + `, ` + reqID + ` from upd, old
+)
+select ` + subColumns + ` from upd`
+}
+
+// notFoundOrConflict строка не изменилась: записи нет или её версия не совпала с ожидаемой
+func (r *PGRepo) notFoundOrConflict(ctx context.Context, id string, version int) error {
+	if version == 0 {
+		return domain.ErrNotFound
+	}
+	var exists bool
+	if err := r.db.QueryRow(ctx, `select exists(select 1 from subscriptions where id=$1 and deleted_at is null)`, id).Scan(&exists); err != nil {
+		return mapErr(err)
+	}
+	if exists {
+		return domain.ErrConflict
+	}
+	return domain.ErrNotFound
+}
+
+// chargedMonthsCTE общая часть расчётов стоимости: по строке на каждый активный месяц подписки
+// amount — сумма списаний в этом месяце с учётом периода оплаты, в валюте подписки
+// value — та же сумма в целевой валюте $5 по курсу месяца списания, NULL если курса нет
+// $1 user_id
+// $2 service_name
+// $3 from
+// $4 to
+// $5 целевая валюта
+// $6 учитывать мягко удалённые
+// У подписок с day_precision неполные месяцы monthly считаются пропорционально дням,
+// списания weekly/quarterly/yearly после end_date не учитываются
+// Вводные фазы помесячные при любом периоде оплаты, обычные списания идут после них
+const chargedMonthsCTE = `
 -- Если $1 или $2 = NULL, то условие даёт TRUE и не сужает выборку
 
 with filtered as (
-  select price, start_date, end_date from subscriptions
+  select id, service_name, user_id, price, intro_phases, currency, billing_period, start_date, end_date, day_precision
+  from subscriptions
   where ($1::uuid is null or user_id = $1::uuid)
     and ($2::text is null or service_name ilike $2)
+    and ($6::bool or deleted_at is null)
 ),
 
 -- обрезаем подписку рамками периода
@@ -163,35 +569,214 @@ with filtered as (
 
 clamped as (
   select
-    price,
+    id, service_name, user_id, price, intro_phases, currency, billing_period, start_date, end_date, day_precision,
     greatest(date_trunc('month', start_date), date_trunc('month', $3::date)) as s,
     least(date_trunc('month', coalesce(end_date, $4::date)), date_trunc('month', $4::date)) as e
   from filtered
 ),
 
--- считаем месяцы включительно
+-- раскладываем подписку по месяцам включительно, если e < s строк не будет
+-- price — цена, действовавшая в месяце: цена вводной фазы, в которую попал месяц, иначе
+-- последняя смена с effective_from <= месяца, иначе исходная. upto — номер месяца, на котором фаза заканчивается
+-- mi — номер месяца от начала подписки, ip — сколько месяцев длятся все вводные фазы
+-- d0/d1 — дни от начала обычных списаний (start_date + ip месяцев) до границ месяца (с day_precision — не дальше end_date)
+-- active_days/month_days — дни подписки в месяце и дни месяца, для пропорции с day_precision
+-- месяцы на паузе пропускаем, график списаний при этом не сдвигается
 
-counts AS (
-  SELECT
-    price,
-    ( ((EXTRACT(YEAR FROM e) * 12 + EXTRACT(MONTH FROM e))::int)
-    -  ((EXTRACT(YEAR FROM s) * 12 + EXTRACT(MONTH FROM s))::int) + 1 ) AS months
-  FROM clamped
-  WHERE e >= s
-)
+months as (
+  select
+    c.id, c.service_name, c.user_id,
+    coalesce((
+      select (ph.value->>'price')::int
+      from (select value, sum((value->>'months')::int) over (order by ord) as upto
+            from jsonb_array_elements(c.intro_phases) with ordinality as t(value, ord)) ph
+      where ph.upto > mm.mi
+      order by ph.upto limit 1), (
+      select pc.price from subscription_price_changes pc
+      where pc.subscription_id = c.id and pc.effective_from <= m
+      order by pc.effective_from desc limit 1), c.price) as price,
+    c.currency, c.billing_period, c.start_date, c.end_date, c.day_precision, m::date as month, mm.mi, ix.ip,
+    greatest(m::date - ix.a, 0) as d0,
+    (case when c.day_precision and c.end_date is not null
+          then least((m + interval '1 month')::date, c.end_date + 1)
+          else (m + interval '1 month')::date end) - ix.a - 1 as d1,
+    least(coalesce(c.end_date, 'infinity'::date), (m + interval '1 month')::date - 1)
+      - greatest(c.start_date, m::date) + 1 as active_days,
+    (m + interval '1 month')::date - m::date as month_days
+  from clamped c
+  cross join lateral generate_series(c.s, c.e, interval '1 month') as m
+  cross join lateral (
+    select ((extract(year from m) * 12 + extract(month from m))
+      - (extract(year from c.start_date) * 12 + extract(month from c.start_date)))::int as mi) mm
+  cross join lateral (
+    select ip, (c.start_date + make_interval(months => ip))::date as a
+    from (select coalesce(sum((value->>'months')::int), 0)::int as ip
+          from jsonb_array_elements(c.intro_phases)) t) ix
+  where not exists (
+    select 1 from subscription_pauses p
+    where p.subscription_id = c.id and p.from_month <= m and (p.to_month is null or p.to_month >= m))
+),
+
+-- amount — сколько списано в месяце: месяцы вводных фаз (mi < ip) списываются как monthly по цене фазы,
+-- дальше списания идут в даты start_date + ip месяцев + k периодов
+-- для weekly считаем, сколько дат start_date + ip месяцев + 7k попало в месяц
+-- monthly с day_precision — доля цены по дням, quarterly/yearly — только если дата списания не позже end_date
+
+charged as (
+  select
+    id, service_name, user_id, currency, month,
+    case when mi < ip or billing_period = 'monthly'
+           then case when day_precision then price * active_days::numeric / month_days else price end
+         when billing_period = 'quarterly'
+           then case when (mi - ip) % 3 = 0 and charge_date <= coalesce(end_date, 'infinity'::date) then price else 0 end
+         when billing_period = 'yearly'
+           then case when (mi - ip) % 12 = 0 and charge_date <= coalesce(end_date, 'infinity'::date) then price else 0 end
+         when billing_period = 'weekly' then price * greatest(d1 / 7 - (d0 + 6) / 7 + 1, 0)
+    end as amount
+  from months
+  cross join lateral (select (start_date + make_interval(months => mi))::date as charge_date) cd
+),
+
+-- пересчёт через рубли: курс — последний с month <= месяца списания, у RUB курс 1
+
+converted as (
+  select
+    ch.id, ch.service_name, ch.user_id, ch.month, ch.amount,
+    case when ch.currency = $5::text then ch.amount::numeric
+         else ch.amount * src.rate / dst.rate end as value
+  from charged ch
+  cross join lateral (
+    select case when ch.currency = 'RUB' then 1::numeric else (
+      select er.rate from exchange_rates er
+      where er.currency = ch.currency and er.month <= ch.month
+      order by er.month desc limit 1) end as rate
+  ) src
+  cross join lateral (
+    select case when $5::text = 'RUB' then 1::numeric else (
+      select er.rate from exchange_rates er
+      where er.currency = $5::text and er.month <= ch.month
+      order by er.month desc limit 1) end as rate
+  ) dst
+)`
+
+// roundRub SQL-выражение округления x до рублей по правилу из параметра rule (domain.Rounding)
+func roundRub(x, rule string) string {
+	return `(case ` + rule + `::text when 'down' then floor(` + x + `) when 'up' then ceil(` + x + `) else round(` + x + `) end)::bigint`
+}
+
+// CalcTotal Сумма списаний за период в валюте f.Currency и число оплачиваемых месяцев
+// $7 правило округления
+func (r *PGRepo) CalcTotal(ctx context.Context, f CostFilter) (int64, int, error) {
+	q := chargedMonthsCTE + `
 
 -- Соединяем , COALESCE даёт нули, если ничего не нашлось
 select
-  coalesce(sum((price * months)::bigint), 0) as total,
-  coalesce(sum(months), 0) as months_counted
-from counts;`
+  ` + roundRub("coalesce(sum(value), 0)", "$7") + ` as total,
+  count(*) as months_counted,
+  count(*) filter (where amount > 0 and value is null) as missing_rates
+from converted;`
 	var total int64
-	var months int
-	err := r.db.QueryRow(ctx, q, userID, serviceName, from, to).Scan(&total, &months)
-	return total, months, err
+	var months, missing int
+	err := r.db.QueryRow(ctx, q, f.UserID, f.ServiceName, f.From, f.To, f.Currency, f.IncludeDeleted, f.Rounding).Scan(&total, &months, &missing)
+	if err != nil {
+		return 0, 0, mapErr(err)
+	}
+	if missing > 0 {
+		return 0, 0, domain.ErrNoExchangeRate
+	}
+	return total, months, nil
+}
+
+// breakdownQuery помесячная разбивка поверх chargedMonthsCTE, строки читаются scanMonthlyCost
+// $7 группировать по service_name
+// $8 группировать по user_id
+// $9 правило округления
+var breakdownQuery = chargedMonthsCTE + `
+
+select
+  month,
+  case when $7::bool then service_name end as service_name,
+  case when $8::bool then user_id::text end as user_id,
+  ` + roundRub("coalesce(sum(value), 0)", "$9") + ` as total,
+  count(*) filter (where amount > 0) as subscriptions,
+  count(*) filter (where amount > 0 and value is null) as missing_rates
+from converted
+group by 1, 2, 3
+order by 1, 2, 3`
+
+// Аргументы для breakdownQuery
+func breakdownArgs(f CostFilter, g BreakdownGroup) []any {
+	return []any{f.UserID, f.ServiceName, f.From, f.To, f.Currency, f.IncludeDeleted, g.ByService, g.ByUser, f.Rounding}
+}
+
+// CalcBreakdown Помесячная разбивка суммы за период с теми же фильтрами, что и CalcTotal
+// При группировке в строке заполняются service_name и/или user_id
+func (r *PGRepo) CalcBreakdown(ctx context.Context, f CostFilter, g BreakdownGroup) ([]domain.MonthlyCost, error) {
+	rows, err := r.db.Query(ctx, breakdownQuery, breakdownArgs(f, g)...)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	defer rows.Close()
+	res := make([]domain.MonthlyCost, 0, 16)
+	for rows.Next() {
+		c, err := scanMonthlyCost(rows)
+		if err != nil {
+			return nil, mapErr(err)
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// CalcByService Сумма за период по каждому сервису с теми же фильтрами, что и CalcTotal
+// $7 правило округления, округляем сумму сервиса целиком
+func (r *PGRepo) CalcByService(ctx context.Context, f CostFilter) ([]domain.ServiceCost, error) {
+	q := chargedMonthsCTE + `
+
+select
+  service_name,
+  ` + roundRub("coalesce(sum(value), 0)", "$7") + ` as total,
+  count(*) filter (where amount > 0 and value is null) as missing_rates
+from converted
+group by service_name
+order by service_name`
+
+	rows, err := r.db.Query(ctx, q, f.UserID, f.ServiceName, f.From, f.To, f.Currency, f.IncludeDeleted, f.Rounding)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	defer rows.Close()
+	res := make([]domain.ServiceCost, 0, 16)
+	for rows.Next() {
+		var c domain.ServiceCost
+		var missing int
+		if err := rows.Scan(&c.ServiceName, &c.Total, &missing); err != nil {
+			return nil, mapErr(err)
+		}
+		if missing > 0 {
+			return nil, domain.ErrNoExchangeRate
+		}
+		res = append(res, c)
+	}
+	return res, mapErr(rows.Err())
+}
+
+// scanMonthlyCost строка breakdownQuery, без курса для списания отдаём ErrNoExchangeRate
+func scanMonthlyCost(r pgx.Row) (domain.MonthlyCost, error) {
+	var c domain.MonthlyCost
+	var missing int
+	if err := r.Scan(&c.Month, &c.ServiceName, &c.UserID, &c.Total, &c.Subscriptions, &missing); err != nil {
+		return c, err
+	}
+	if missing > 0 {
+		return c, domain.ErrNoExchangeRate
+	}
+	c.Month = domain.MonthStart(c.Month)
+	return c, nil
 }
 
-// scanSub хелпер для Scan
+// scanSub хелпер для Scan, порядок полей как в subColumns
 func scanSub(r pgx.Row, s *domain.Subscription) error {
-	return r.Scan(&s.ID, &s.ServiceName, &s.Price, &s.UserID, &s.StartDate, &s.EndDate)
+	return r.Scan(&s.ID, &s.ServiceName, &s.Price, &s.Currency, &s.BillingPeriod, &s.UserID, &s.StartDate, &s.EndDate, &s.Version,
+		&s.CreatedAt, &s.UpdatedAt, &s.CreatedBy, &s.UpdatedBy, &s.DeletedAt, &s.DayPrecision, &s.IntroPhases)
 }
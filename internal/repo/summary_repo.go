@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+)
+
+// userSummaryQuery сводка пользователя одним запросом поверх chargedMonthsCTE
+// run — подписки, действующие в месяце $4, с ценой этого месяца, приведённой к месяцу и пересчитанной в $5
+// (цена вводной фазы уже месячная)
+// ends — не более $8 подписок, действующих по $7 или дольше (end_date помесячной подписки — весь её месяц), по возрастанию даты
+var userSummaryQuery = chargedMonthsCTE + `,
+
+run as (
+  select
+    m.id, m.service_name,
+    m.price * (case when m.mi < m.ip then 1::numeric else case m.billing_period
+      when 'monthly' then 1::numeric
+      when 'weekly' then 52::numeric / 12
+      when 'quarterly' then 1::numeric / 3
+      when 'yearly' then 1::numeric / 12 end end)
+    * (case when m.currency = $5::text then 1::numeric else src.rate / dst.rate end) as value
+  from months m
+  cross join lateral (
+    select case when m.currency = 'RUB' then 1::numeric else (
+      select er.rate from exchange_rates er
+      where er.currency = m.currency and er.month <= m.month
+      order by er.month desc limit 1) end as rate
+  ) src
+  cross join lateral (
+    select case when $5::text = 'RUB' then 1::numeric else (
+      select er.rate from exchange_rates er
+      where er.currency = $5::text and er.month <= m.month
+      order by er.month desc limit 1) end as rate
+  ) dst
+  where m.month = $4::date
+),
+
+top as (
+  select service_name, sum(value) as value
+  from run
+  group by service_name
+  order by value desc nulls last, service_name
+  limit 1
+),
+
+ends as (
+  select id, service_name, end_date, day_precision
+  from filtered
+  where (case when day_precision then end_date
+              else (end_date + interval '1 month')::date - 1 end) >= $7::date
+  order by end_date, service_name
+  limit $8
+)
+
+select
+  (select count(distinct id) from run),
+  (select ` + roundRub("coalesce(sum(value), 0)", "$9") + ` from run),
+  (select count(*) filter (where value is null) from run),
+  (select ` + roundRub("coalesce(sum(value), 0)", "$9") + ` from converted),
+  (select count(*) filter (where amount > 0 and value is null) from converted),
+  (select service_name from top),
+  (select ` + roundRub("value", "$9") + ` from top),
+  (select coalesce(array_agg(id::text order by end_date, service_name), '{}') from ends),
+  (select coalesce(array_agg(service_name order by end_date, service_name), '{}') from ends),
+  (select coalesce(array_agg(end_date order by end_date, service_name), '{}') from ends),
+  (select coalesce(array_agg(day_precision order by end_date, service_name), '{}') from ends)`
+
+// UserSummary Сводка по подпискам пользователя f.UserID: активные и run-rate на месяц f.To,
+// расходы за f.From..f.To и до upcoming ближайших окончаний начиная с today
+func (r *PGRepo) UserSummary(ctx context.Context, f CostFilter, today time.Time, upcoming int) (*domain.UserSummary, error) {
+	var (
+		s                 domain.UserSummary
+		missRun, missLife int
+		topName           *string
+		topValue          *int64
+		ids, names        []string
+		ends              []time.Time
+		dayPrecision      []bool
+	)
+	err := r.db.QueryRow(ctx, userSummaryQuery,
+		f.UserID, f.ServiceName, f.From, f.To, f.Currency, f.IncludeDeleted, today, upcoming, f.Rounding,
+	).Scan(&s.ActiveSubscriptions, &s.MonthlyRunRate, &missRun, &s.LifetimeSpend, &missLife,
+		&topName, &topValue, &ids, &names, &ends, &dayPrecision)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	if missRun > 0 || missLife > 0 {
+		return nil, domain.ErrNoExchangeRate
+	}
+	if topName != nil && topValue != nil {
+		s.TopService = &domain.ServiceCost{ServiceName: *topName, Total: *topValue}
+	}
+	s.UpcomingEnds = make([]domain.UpcomingEnd, 0, len(ids))
+	for i := range ids {
+		s.UpcomingEnds = append(s.UpcomingEnds, domain.UpcomingEnd{
+			SubscriptionID: ids[i], ServiceName: names[i], EndDate: ends[i], DayPrecision: dayPrecision[i],
+		})
+	}
+	return &s, nil
+}
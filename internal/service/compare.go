@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/repo"
+)
+
+// Способы выбора базового периода сравнения
+const (
+	comparePreviousPeriod = "previous_period"
+	comparePreviousYear   = "previous_year"
+)
+
+// CostCompare Сравнение сумм текущего периода from/to с базовым и вклад каждого сервиса в изменение
+// Обе суммы считаются тем же расчётом, что и CalcTotal; итог периода — сумма по сервисам,
+// поэтому вклады сервисов в точности складываются в общее изменение
+func (s *Service) CostCompare(ctx context.Context, q dto.CostCompareQuery) (dto.CostCompareResponse, error) {
+	cur, _, err := s.breakdownParams(dto.CostBreakdownQuery{TotalCostQuery: q.TotalCostQuery})
+	if err != nil {
+		return dto.CostCompareResponse{}, err
+	}
+	prev, err := comparePeriod(q, cur)
+	if err != nil {
+		return dto.CostCompareResponse{}, err
+	}
+
+	curRows, err := s.repo.CalcByService(ctx, cur)
+	if err != nil {
+		return dto.CostCompareResponse{}, err
+	}
+	prevRows, err := s.repo.CalcByService(ctx, prev)
+	if err != nil {
+		return dto.CostCompareResponse{}, err
+	}
+
+	res := dto.CostCompareResponse{
+		Current:  dto.CostComparePeriod{From: cur.From.Format("01-2006"), To: cur.To.Format("01-2006")},
+		Previous: dto.CostComparePeriod{From: prev.From.Format("01-2006"), To: prev.To.Format("01-2006")},
+		Currency: cur.Currency,
+	}
+	items := make(map[string]*dto.CostCompareItem, len(curRows)+len(prevRows))
+	item := func(name string) *dto.CostCompareItem {
+		it, ok := items[name]
+		if !ok {
+			it = &dto.CostCompareItem{ServiceName: name}
+			items[name] = it
+		}
+		return it
+	}
+	for _, c := range curRows {
+		item(c.ServiceName).Current = c.Total
+		res.Current.Total += c.Total
+	}
+	for _, c := range prevRows {
+		item(c.ServiceName).Previous = c.Total
+		res.Previous.Total += c.Total
+	}
+	res.Delta = res.Current.Total - res.Previous.Total
+	res.DeltaPercent = percent(res.Delta, res.Previous.Total)
+
+	res.Items = make([]dto.CostCompareItem, 0, len(items))
+	for _, it := range items {
+		it.Delta = it.Current - it.Previous
+		it.Contribution = percent(it.Delta, res.Delta)
+		res.Items = append(res.Items, *it)
+	}
+	// Сначала сервисы, сильнее всего изменившие сумму
+	sort.Slice(res.Items, func(i, j int) bool {
+		a, b := res.Items[i], res.Items[j]
+		if da, db := abs64(a.Delta), abs64(b.Delta); da != db {
+			return da > db
+		}
+		return a.ServiceName < b.ServiceName
+	})
+	return res, nil
+}
+
+// comparePeriod Фильтр базового периода: явный compare_from/compare_to или сдвиг текущего по compare
+func comparePeriod(q dto.CostCompareQuery, cur repo.CostFilter) (repo.CostFilter, error) {
+	prev := cur
+	if q.CompareFrom != "" || q.CompareTo != "" {
+		if q.Compare != "" {
+			return prev, domain.Invalid("compare", domain.CodeInvalidValue, "compare must not be combined with compare_from/compare_to")
+		}
+		from, err := parseMonth(q.CompareFrom)
+		if err != nil {
+			return prev, domain.Invalid("compare_from", domain.CodeInvalidFormat, fmt.Sprintf("invalid compare_from: %v", err))
+		}
+		to, err := parseMonth(q.CompareTo)
+		if err != nil {
+			return prev, domain.Invalid("compare_to", domain.CodeInvalidFormat, fmt.Sprintf("invalid compare_to: %v", err))
+		}
+		if to.Before(from) {
+			return prev, domain.Invalid("compare_to", domain.CodeOutOfRange, "compare_to must not be before compare_from")
+		}
+		prev.From, prev.To = from, to
+		return prev, nil
+	}
+
+	switch q.Compare {
+	case "", comparePreviousPeriod:
+		n := (cur.To.Year()-cur.From.Year())*12 + int(cur.To.Month()-cur.From.Month()) + 1
+		prev.From, prev.To = cur.From.AddDate(0, -n, 0), cur.From.AddDate(0, -1, 0)
+	case comparePreviousYear:
+		prev.From, prev.To = cur.From.AddDate(-1, 0, 0), cur.To.AddDate(-1, 0, 0)
+	default:
+		return prev, domain.Invalid("compare", domain.CodeInvalidValue, fmt.Sprintf("invalid compare: %q", q.Compare))
+	}
+	return prev, nil
+}
+
+// percent Доля part от base в процентах с точностью до сотых, nil при base == 0
+func percent(part, base int64) *float64 {
+	if base == 0 {
+		return nil
+	}
+	p := math.Round(float64(part)/float64(base)*10000) / 100
+	return &p
+}
+
+func abs64(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/repo"
+)
+
+func TestComparePeriod(t *testing.T) {
+	cur := repo.CostFilter{From: date(2025, 1, 1), To: date(2025, 3, 1), Currency: "RUB"}
+	tests := []struct {
+		name             string
+		q                dto.CostCompareQuery
+		cur              repo.CostFilter
+		wantFrom, wantTo time.Time
+		wantField        string // поле ошибки валидации
+	}{
+		{name: "default is previous period", cur: cur,
+			wantFrom: date(2024, 10, 1), wantTo: date(2024, 12, 1)},
+		{name: "previous period", q: dto.CostCompareQuery{Compare: "previous_period"}, cur: cur,
+			wantFrom: date(2024, 10, 1), wantTo: date(2024, 12, 1)},
+		{name: "previous period of one month", cur: repo.CostFilter{From: date(2025, 3, 1), To: date(2025, 3, 1)},
+			wantFrom: date(2025, 2, 1), wantTo: date(2025, 2, 1)},
+		{name: "previous period across years", cur: repo.CostFilter{From: date(2024, 11, 1), To: date(2025, 2, 1)},
+			wantFrom: date(2024, 7, 1), wantTo: date(2024, 10, 1)},
+		{name: "previous year", q: dto.CostCompareQuery{Compare: "previous_year"}, cur: cur,
+			wantFrom: date(2024, 1, 1), wantTo: date(2024, 3, 1)},
+		{name: "explicit range", q: dto.CostCompareQuery{CompareFrom: "06-2024", CompareTo: "08-2024"}, cur: cur,
+			wantFrom: date(2024, 6, 1), wantTo: date(2024, 8, 1)},
+		{name: "explicit range with compare", q: dto.CostCompareQuery{Compare: "previous_year", CompareFrom: "06-2024", CompareTo: "08-2024"},
+			cur: cur, wantField: "compare"},
+		{name: "explicit range without to", q: dto.CostCompareQuery{CompareFrom: "06-2024"}, cur: cur, wantField: "compare_to"},
+		{name: "explicit range reversed", q: dto.CostCompareQuery{CompareFrom: "08-2024", CompareTo: "06-2024"}, cur: cur,
+			wantField: "compare_to"},
+		{name: "unknown compare", q: dto.CostCompareQuery{Compare: "last_quarter"}, cur: cur, wantField: "compare"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := comparePeriod(tt.q, tt.cur)
+			if tt.wantField != "" {
+				var ve *domain.ValidationError
+				if !errors.As(err, &ve) || !ve.Has(tt.wantField) {
+					t.Fatalf("comparePeriod() error = %v, want validation error on %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("comparePeriod() error = %v", err)
+			}
+			if !got.From.Equal(tt.wantFrom) || !got.To.Equal(tt.wantTo) {
+				t.Errorf("comparePeriod() = %v..%v, want %v..%v", got.From, got.To, tt.wantFrom, tt.wantTo)
+			}
+			if got.Currency != tt.cur.Currency {
+				t.Errorf("filters not copied: currency %q, want %q", got.Currency, tt.cur.Currency)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		part, base int64
+		want       *float64
+	}{
+		{part: 700, base: 4900, want: ptr(14.29)},
+		{part: -500, base: 1000, want: ptr(-50.0)},
+		{part: 0, base: 1000, want: ptr(0.0)},
+		{part: 100, base: 0},
+	}
+	for _, tt := range tests {
+		got := percent(tt.part, tt.base)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("percent(%d, %d) = %v, want %v", tt.part, tt.base, got, tt.want)
+		}
+	}
+}
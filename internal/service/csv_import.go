@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+)
+
+// csvFields поля dto.CreateSubscriptionRequest, которые читаем из CSV
+var csvFields = []string{"service_name", "price", "currency", "billing_period", "user_id", "start_date", "end_date"}
+
+// ImportCSV Разбираем CSV с заголовком в dto.CreateSubscriptionRequest и создаём подписки через CreateBatch,
+// чтобы правила валидации были те же, что у POST /subscriptions. Ошибки отдаём по номерам строк файла
+func (s *Service) ImportCSV(ctx context.Context, r io.Reader, opts dto.CSVImportOptions) (*dto.CSVImportResponse, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // число колонок проверяем сами, чтобы отдать ошибку по строке
+	cr.TrimLeadingSpace = true
+	if opts.Delimiter != 0 {
+		cr.Comma = opts.Delimiter
+	}
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, domain.Invalid("file", domain.CodeRequired, "csv is empty")
+	}
+	if err != nil {
+		return nil, domain.Invalid("file", domain.CodeInvalidFormat, fmt.Sprintf("invalid csv: %v", err))
+	}
+	cols, err := csvColumns(header, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.CSVImportResponse{DryRun: opts.DryRun, Rows: make([]dto.CSVRowResult, 0, 64)}
+	items := make([]dto.CreateSubscriptionRequest, 0, 64)
+	rowOf := make([]int, 0, 64) // индекс в res.Rows для каждой записи в items
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// сломанные кавычки и т.п., дальше файл читать нельзя
+			return nil, domain.Invalid("file", domain.CodeInvalidFormat, fmt.Sprintf("invalid csv: %v", err))
+		}
+		if len(res.Rows) == maxBatchSize {
+			return nil, domain.Invalid("file", domain.CodeOutOfRange, fmt.Sprintf("too many rows: max %d", maxBatchSize))
+		}
+		line, _ := cr.FieldPos(0)
+		req, err := csvRecord(rec, len(header), cols, opts.UserID)
+		if err != nil {
+			res.Rows = append(res.Rows, dto.CSVRowResult{Line: line, Status: dto.BatchStatusInvalid, Error: err.Error(), Errors: fieldErrors(err)})
+			res.Failed++
+			continue
+		}
+		rowOf = append(rowOf, len(res.Rows))
+		res.Rows = append(res.Rows, dto.CSVRowResult{Line: line})
+		items = append(items, req)
+	}
+	if len(res.Rows) == 0 {
+		return nil, domain.Invalid("file", domain.CodeRequired, "csv has no data rows")
+	}
+	if len(items) == 0 {
+		return res, nil
+	}
+
+	// Строки, которые не разобрались, CreateBatch не видит,
+	// поэтому в режиме "всё или ничего" остальные только проверяем
+	abort := res.Failed > 0 && !opts.BestEffort && !opts.DryRun
+	batch, err := s.CreateBatch(ctx, dto.BatchCreateRequest{
+		Items: items, BestEffort: opts.BestEffort, DryRun: opts.DryRun || abort,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for k, br := range batch.Results {
+		row := &res.Rows[rowOf[k]]
+		row.Status, row.Error, row.Errors = br.Status, br.Error, br.Errors
+		if abort && br.Status == dto.BatchStatusValid {
+			row.Status = dto.BatchStatusSkipped
+		}
+		if br.Item != nil {
+			row.ID = br.Item.ID
+		}
+	}
+	res.Created = batch.Created
+	res.Failed += batch.Failed
+	return res, nil
+}
+
+// csvColumns номер колонки в файле для каждого поля, -1 если колонки нет
+func csvColumns(header []string, opts dto.CSVImportOptions) (map[string]int, error) {
+	for f := range opts.Columns {
+		if !isCSVField(f) {
+			return nil, domain.Invalid("col_"+f, domain.CodeInvalidValue, fmt.Sprintf("unknown column mapping field %q", f))
+		}
+	}
+	// BOM, который оставляет Excel в начале файла
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\uFEFF")
+	}
+	cols := make(map[string]int, len(csvFields))
+	for _, f := range csvFields {
+		name := f
+		if v, ok := opts.Columns[f]; ok && v != "" {
+			name = v
+		}
+		cols[f] = -1
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				cols[f] = i
+				break
+			}
+		}
+	}
+	required := []string{"service_name", "price", "start_date"}
+	if opts.UserID == "" {
+		required = append(required, "user_id")
+	}
+	for _, f := range required {
+		if cols[f] < 0 {
+			return nil, domain.Invalid(f, domain.CodeRequired, "csv has no column for "+f)
+		}
+	}
+	return cols, nil
+}
+
+// csvRecord строка CSV в тело создания, валидацию полей делает CreateBatch
+func csvRecord(rec []string, width int, cols map[string]int, userID string) (dto.CreateSubscriptionRequest, error) {
+	var req dto.CreateSubscriptionRequest
+	if len(rec) != width {
+		return req, domain.Invalid("row", domain.CodeInvalidFormat, fmt.Sprintf("expected %d columns, got %d", width, len(rec)))
+	}
+	get := func(f string) string {
+		if i := cols[f]; i >= 0 {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	req.ServiceName = get("service_name")
+	req.Currency = get("currency")
+	req.BillingPeriod = get("billing_period")
+	req.UserID = get("user_id")
+	if req.UserID == "" {
+		req.UserID = userID
+	}
+	req.StartDate = get("start_date")
+	if v := get("end_date"); v != "" {
+		req.EndDate = &v
+	}
+	if v := get("price"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil {
+			return req, domain.Invalid("price", domain.CodeInvalidFormat, "invalid price: expected integer")
+		}
+		req.Price = p
+	}
+	return req, nil
+}
+
+func isCSVField(f string) bool {
+	return slices.Contains(csvFields, f)
+}
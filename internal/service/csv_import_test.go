@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+)
+
+func TestCSVColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		opts   dto.CSVImportOptions
+		want   map[string]int
+		field  string // поле ошибки, пусто — заголовок разобрался
+		code   string
+	}{
+		{
+			name:   "default names",
+			header: []string{"service_name", "price", "currency", "billing_period", "user_id", "start_date", "end_date"},
+			want: map[string]int{"service_name": 0, "price": 1, "currency": 2, "billing_period": 3,
+				"user_id": 4, "start_date": 5, "end_date": 6},
+		},
+		{
+			name:   "bom, case and spaces",
+			header: []string{"\uFEFFService_Name", " PRICE ", "start_date", "user_id"},
+			want: map[string]int{"service_name": 0, "price": 1, "currency": -1, "billing_period": -1,
+				"user_id": 3, "start_date": 2, "end_date": -1},
+		},
+		{
+			name:   "mapped columns",
+			header: []string{"Сервис", "Цена", "Начало"},
+			opts: dto.CSVImportOptions{
+				Columns: map[string]string{"service_name": "сервис", "price": "Цена", "start_date": "Начало"},
+				UserID:  "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+			},
+			want: map[string]int{"service_name": 0, "price": 1, "currency": -1, "billing_period": -1,
+				"user_id": -1, "start_date": 2, "end_date": -1},
+		},
+		{
+			name:   "empty mapping keeps default name",
+			header: []string{"service_name", "price", "start_date", "user_id"},
+			opts:   dto.CSVImportOptions{Columns: map[string]string{"price": ""}},
+			want: map[string]int{"service_name": 0, "price": 1, "currency": -1, "billing_period": -1,
+				"user_id": 3, "start_date": 2, "end_date": -1},
+		},
+		{
+			name:   "unknown mapping field",
+			header: []string{"service_name", "price", "start_date", "user_id"},
+			opts:   dto.CSVImportOptions{Columns: map[string]string{"cost": "price"}},
+			field:  "col_cost", code: domain.CodeInvalidValue,
+		},
+		{
+			name:   "missing price",
+			header: []string{"service_name", "start_date", "user_id"},
+			field:  "price", code: domain.CodeRequired,
+		},
+		{
+			name:   "missing user_id without default",
+			header: []string{"service_name", "price", "start_date"},
+			field:  "user_id", code: domain.CodeRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := csvColumns(tt.header, tt.opts)
+			if tt.field != "" {
+				if f := firstField(err); f.Field != tt.field || f.Code != tt.code {
+					t.Fatalf("csvColumns() error = %v, want %s on %s", err, tt.code, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("csvColumns() error = %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("csvColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVRecord(t *testing.T) {
+	const user = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	cols := map[string]int{"service_name": 0, "price": 1, "currency": -1, "billing_period": -1,
+		"user_id": 2, "start_date": 3, "end_date": 4}
+	tests := []struct {
+		name  string
+		rec   []string
+		want  dto.CreateSubscriptionRequest
+		field string // поле ошибки, пусто — строка разобралась
+		code  string
+	}{
+		{
+			name: "full row",
+			rec:  []string{" Yandex Plus ", "400", "11111111-2bf1-4721-ae6f-7636e79a0cba", "07-2025", "12-2025"},
+			want: dto.CreateSubscriptionRequest{ServiceName: "Yandex Plus", Price: 400,
+				UserID: "11111111-2bf1-4721-ae6f-7636e79a0cba", StartDate: "07-2025", EndDate: ptr("12-2025")},
+		},
+		{
+			name: "default user and no end",
+			rec:  []string{"Yandex Plus", "400", "", "07-2025", ""},
+			want: dto.CreateSubscriptionRequest{ServiceName: "Yandex Plus", Price: 400, UserID: user, StartDate: "07-2025"},
+		},
+		{
+			name: "empty price left to validation",
+			rec:  []string{"Yandex Plus", "", "", "07-2025", ""},
+			want: dto.CreateSubscriptionRequest{ServiceName: "Yandex Plus", UserID: user, StartDate: "07-2025"},
+		},
+		{name: "bad price", rec: []string{"Yandex Plus", "4.5", "", "07-2025", ""}, field: "price", code: domain.CodeInvalidFormat},
+		{name: "short row", rec: []string{"Yandex Plus", "400"}, field: "row", code: domain.CodeInvalidFormat},
+		{name: "long row", rec: []string{"Yandex Plus", "400", "", "07-2025", "", "x"}, field: "row", code: domain.CodeInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := csvRecord(tt.rec, 5, cols, user)
+			if tt.field != "" {
+				if f := firstField(err); f.Field != tt.field || f.Code != tt.code {
+					t.Fatalf("csvRecord() error = %v, want %s on %s", err, tt.code, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("csvRecord() error = %v", err)
+			}
+			if got.ServiceName != tt.want.ServiceName || got.Price != tt.want.Price || got.UserID != tt.want.UserID ||
+				got.StartDate != tt.want.StartDate || !equalPtr(got.EndDate, tt.want.EndDate) ||
+				got.Currency != tt.want.Currency || got.BillingPeriod != tt.want.BillingPeriod {
+				t.Errorf("csvRecord() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// firstField первая ошибка поля из ValidationError, пустая — если ошибка другая
+func firstField(err error) domain.FieldError {
+	var v *domain.ValidationError
+	if !errors.As(err, &v) || len(v.Fields) == 0 {
+		return domain.FieldError{}
+	}
+	return v.Fields[0]
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+)
+
+// ExportSubscriptions Все подписки по фильтрам и сортировке списка, пагинация не учитывается
+// Записи отдаются в fn по одной по мере чтения из БД
+func (s *Service) ExportSubscriptions(ctx context.Context, q dto.ListQuery, fn func(*dto.SubscriptionResponse) error) error {
+	q.Limit, q.Offset, q.Cursor = "", "", nil
+	f, err := listFilter(q)
+	if err != nil {
+		return err
+	}
+	return s.repo.ExportSubscriptions(ctx, f, func(sub *domain.Subscription) error {
+		return fn(toDTO(sub))
+	})
+}
+
+// ExportBreakdown Помесячная разбивка как в CostBreakdown, строки отдаются в fn по одной
+// Без группировки месяцы без списаний дополняются нулями
+func (s *Service) ExportBreakdown(ctx context.Context, q dto.CostBreakdownQuery, fn func(*dto.CostBreakdownItem) error) error {
+	f, g, err := s.breakdownParams(q)
+	if err != nil {
+		return err
+	}
+	fill := !g.ByService && !g.ByUser
+	next := f.From // первый месяц, который ещё не отдали
+	emit := func(c *domain.MonthlyCost) error {
+		item := monthlyCostToDTO(c)
+		return fn(&item)
+	}
+	err = s.repo.ExportBreakdown(ctx, f, g, func(c *domain.MonthlyCost) error {
+		if fill {
+			for ; next.Before(c.Month); next = next.AddDate(0, 1, 0) {
+				if err := emit(&domain.MonthlyCost{Month: next}); err != nil {
+					return err
+				}
+			}
+			next = c.Month.AddDate(0, 1, 0)
+		}
+		return emit(c)
+	})
+	if err != nil || !fill {
+		return err
+	}
+	for ; !next.After(f.To); next = next.AddDate(0, 1, 0) {
+		if err := emit(&domain.MonthlyCost{Month: next}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/repo"
+)
+
+// Границы горизонта прогноза в месяцах
+const (
+	defaultForecastMonths = 12
+	maxForecastMonths     = 120
+)
+
+// CostForecast Прогноз расходов на q.Months месяцев начиная с текущего
+// Считается тем же расчётом, что и разбивка: учитывает даты окончания, смены цены, паузы и вводные периоды
+// Бессрочные подписки продлеваются на весь горизонт
+func (s *Service) CostForecast(ctx context.Context, q dto.CostForecastQuery) (dto.CostForecastResponse, error) {
+	n, err := parseOptInt(q.Months, 1)
+	if err != nil {
+		return dto.CostForecastResponse{}, domain.Invalid("months", domain.CodeInvalidFormat, fmt.Sprintf("invalid months: %v", err))
+	}
+	if n == 0 {
+		n = defaultForecastMonths
+	}
+	if n > maxForecastMonths {
+		return dto.CostForecastResponse{}, domain.Invalid("months", domain.CodeOutOfRange, fmt.Sprintf("months must be <= %d", maxForecastMonths))
+	}
+	currency, err := parseCurrency(q.Currency)
+	if err != nil {
+		return dto.CostForecastResponse{}, err
+	}
+
+	from := domain.MonthStart(time.Now().UTC())
+	to := from.AddDate(0, n-1, 0)
+	rows, err := s.repo.CalcBreakdown(ctx, repo.CostFilter{
+		From: from, To: to, UserID: q.UserID, ServiceName: q.ServiceName, Currency: currency, Rounding: s.rounding,
+	}, repo.BreakdownGroup{})
+	if err != nil {
+		return dto.CostForecastResponse{}, err
+	}
+
+	res := dto.CostForecastResponse{
+		From:     from.Format("01-2006"),
+		To:       to.Format("01-2006"),
+		Currency: currency,
+		Items:    make([]dto.CostForecastItem, 0, n),
+	}
+	for _, c := range fillMonths(rows, from, to) {
+		res.Total += c.Total
+		res.Items = append(res.Items, dto.CostForecastItem{
+			Month:         c.Month.Format("01-2006"),
+			Total:         c.Total,
+			Cumulative:    res.Total,
+			Subscriptions: c.Subscriptions,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/repo"
+)
+
+// RateService управление курсами валют, по которым пересчитываются суммы
+type RateService struct{ repo repo.RateRepository }
+
+func NewRates(r repo.RateRepository) *RateService { return &RateService{repo: r} }
+
+// List Курсы всех валют или одной, если currency не пустая
+func (s *RateService) List(ctx context.Context, currency string) ([]dto.ExchangeRateResponse, error) {
+	var cur *string
+	if currency != "" {
+		c, err := parseRateCurrency(currency)
+		if err != nil {
+			return nil, err
+		}
+		cur = &c
+	}
+	items, err := s.repo.ListRates(ctx, cur)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.ExchangeRateResponse, 0, len(items))
+	for _, rt := range items {
+		res = append(res, rateToDTO(&rt))
+	}
+	return res, nil
+}
+
+// Set Валидируем и сохраняем курс валюты с месяца month
+func (s *RateService) Set(ctx context.Context, currency, month string, in dto.ExchangeRateRequest) (*dto.ExchangeRateResponse, error) {
+	rt, err := parseRateKey(currency, month)
+	if err != nil {
+		return nil, err
+	}
+	if in.Rate <= 0 {
+		return nil, domain.ErrInvalidRate
+	}
+	rt.Rate = in.Rate
+	if err := s.repo.UpsertRate(ctx, rt); err != nil {
+		return nil, err
+	}
+	out := rateToDTO(rt)
+	return &out, nil
+}
+
+// Delete Удаляем курс валюты за месяц
+func (s *RateService) Delete(ctx context.Context, currency, month string) error {
+	rt, err := parseRateKey(currency, month)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteRate(ctx, rt)
+}
+
+// Курс для базовой валюты не храним, он всегда 1
+func parseRateCurrency(s string) (string, error) {
+	c, err := parseCurrency(s)
+	if err != nil {
+		return "", err
+	}
+	if c == domain.BaseCurrency {
+		return "", domain.Invalid("currency", domain.CodeInvalidValue, fmt.Sprintf("rate for %s is always 1", domain.BaseCurrency))
+	}
+	return c, nil
+}
+
+// Разбираем валюту и месяц, которые идентифицируют курс
+func parseRateKey(currency, month string) (*domain.ExchangeRate, error) {
+	c, err := parseRateCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	m, err := parseMonth(month)
+	if err != nil {
+		return nil, domain.Invalid("month", domain.CodeInvalidFormat, fmt.Sprintf("invalid month: %v", err))
+	}
+	return &domain.ExchangeRate{Currency: c, Month: m}, nil
+}
+
+// rateToDTO маппим курс в ответ
+func rateToDTO(rt *domain.ExchangeRate) dto.ExchangeRateResponse {
+	return dto.ExchangeRateResponse{
+		Currency: rt.Currency,
+		Month:    rt.Month.Format("01-2006"),
+		Rate:     rt.Rate,
+	}
+}
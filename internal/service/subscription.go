@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
@@ -17,53 +22,285 @@ import (
 // парсим/нормализуем даты
 // маппим доменные модели в DTO
 // отдаём понятные ошибки наверх
-type Service struct{ repo repo.SubscriptionRepository }
+// rounding — правило округления сумм с неполными месяцами до рублей
+type Service struct {
+	repo     repo.SubscriptionRepository
+	rounding domain.Rounding
+}
+
+// maxBatchSize сколько записей можно создать одним запросом
+const maxBatchSize = 1000
+
+// maxIntroPhases сколько вводных периодов может быть у подписки
+const maxIntroPhases = 12
 
-func New(r repo.SubscriptionRepository) *Service { return &Service{repo: r} }
+func New(r repo.SubscriptionRepository, rounding domain.Rounding) *Service {
+	return &Service{repo: r, rounding: rounding}
+}
 
 // Create Валидируем поля, парсим даты, запсиываем в бд
 func (s *Service) Create(ctx context.Context, in dto.CreateSubscriptionRequest) (*dto.SubscriptionResponse, error) {
-	// Валидация и парсинг
+	sub, err := newSubscription(in)
+	if err != nil {
+		return nil, err
+	}
+	// вызываем repo. Create
+	created, err := s.repo.Create(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	return toDTO(created), nil
+}
+
+// CreateBatch Массовое создание: каждую запись валидируем по правилам Create, валидные вставляем одной транзакцией
+// Без BestEffort любая невалидная запись отменяет вставку всех, с DryRun только валидируем
+func (s *Service) CreateBatch(ctx context.Context, in dto.BatchCreateRequest) (*dto.BatchCreateResponse, error) {
+	if len(in.Items) == 0 {
+		return nil, domain.Invalid("items", domain.CodeRequired, "items must not be empty")
+	}
+	if len(in.Items) > maxBatchSize {
+		return nil, domain.Invalid("items", domain.CodeOutOfRange, fmt.Sprintf("too many items: max %d", maxBatchSize))
+	}
+
+	res := &dto.BatchCreateResponse{DryRun: in.DryRun, Results: make([]dto.BatchItemResult, len(in.Items))}
+	valid := make([]*domain.Subscription, 0, len(in.Items))
+	idx := make([]int, 0, len(in.Items)) // номер в запросе для каждой валидной записи
+	for i, item := range in.Items {
+		res.Results[i].Index = i
+		sub, err := newSubscription(item)
+		if err != nil {
+			res.Results[i].Status = dto.BatchStatusInvalid
+			res.Results[i].Error, res.Results[i].Errors = err.Error(), fieldErrors(err)
+			res.Failed++
+			continue
+		}
+		valid = append(valid, sub)
+		idx = append(idx, i)
+	}
+
+	if in.DryRun {
+		for _, i := range idx {
+			res.Results[i].Status = dto.BatchStatusValid
+		}
+		return res, nil
+	}
+	// Всё или ничего: при ошибках валидации валидные записи пропускаем
+	if res.Failed > 0 && !in.BestEffort {
+		for _, i := range idx {
+			res.Results[i].Status = dto.BatchStatusSkipped
+		}
+		return res, nil
+	}
+	if len(valid) > 0 {
+		created, err := s.repo.CreateBatch(ctx, valid)
+		if err != nil {
+			return nil, err
+		}
+		for k, i := range idx {
+			res.Results[i].Status = dto.BatchStatusCreated
+			res.Results[i].Item = toDTO(&created[k])
+			res.Created++
+		}
+	}
+	return res, nil
+}
+
+// fieldErrors ошибки по полям из domain.ValidationError, для остальных ошибок nil
+func fieldErrors(err error) []dto.FieldError {
+	var ve *domain.ValidationError
+	if !errors.As(err, &ve) {
+		return nil
+	}
+	res := make([]dto.FieldError, 0, len(ve.Fields))
+	for _, f := range ve.Fields {
+		res = append(res, dto.FieldError{Field: f.Field, Code: f.Code, Message: f.Message})
+	}
+	return res
+}
+
+// newSubscription Валидация и парсинг тела создания в доменную модель
+// Проверяем все поля сразу, ошибки собираем в domain.ValidationError
+func newSubscription(in dto.CreateSubscriptionRequest) (*domain.Subscription, error) {
+	var v domain.ValidationError
+	sub := parseSubscription(in, &v)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// parseSubscription разбираем поля тела в доменную модель, ошибки каждого поля добавляем в v
+// Пустой end_date считаем бессрочной подпиской, дата в формате YYYY-MM-DD включает точность до дня
+func parseSubscription(in dto.CreateSubscriptionRequest, v *domain.ValidationError) *domain.Subscription {
+	sub := &domain.Subscription{ServiceName: in.ServiceName, Price: in.Price, UserID: in.UserID}
+	var err error
+
 	if in.ServiceName == "" {
-		return nil, fmt.Errorf("service_name is required")
+		v.Add("service_name", domain.CodeRequired, "service_name is required")
 	}
 	if in.Price <= 0 {
-		return nil, domain.ErrInvalidPrice
+		v.AddErr("price", domain.CodeOutOfRange, domain.ErrInvalidPrice)
+	}
+	if sub.BillingPeriod, err = parseBillingPeriod(in.BillingPeriod); err != nil {
+		v.AddErr("billing_period", domain.CodeInvalidValue, err)
 	}
-	if _, err := uuid.Parse(in.UserID); err != nil {
-		return nil, fmt.Errorf("invalid user_id: %w", err)
+	if sub.Currency, err = parseCurrency(in.Currency); err != nil {
+		v.AddErr("currency", domain.CodeInvalidValue, err)
 	}
-	start, err := parseMonth(in.StartDate)
+	checkUserID(in.UserID, v)
+	sub.IntroPhases = checkIntroPhases(in.IntroPhases, v)
+	var (
+		start subDate
+		end   *subDate
+	)
+	checkDate("start_date", in.StartDate, &start, v)
+	if in.EndDate != nil && *in.EndDate != "" {
+		var e subDate
+		if checkDate("end_date", *in.EndDate, &e, v) {
+			end = &e
+		}
+	}
+	sub.StartDate, sub.EndDate, sub.DayPrecision = subDates(start, end)
+	checkDates(sub.StartDate, sub.EndDate, v)
+	return sub
+}
+
+// checkUserID user_id обязателен и должен быть UUID
+func checkUserID(id string, v *domain.ValidationError) {
+	if id == "" {
+		v.Add("user_id", domain.CodeRequired, "user_id is required")
+		return
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		v.Add("user_id", domain.CodeInvalidFormat, fmt.Sprintf("invalid user_id: %v", err))
+	}
+}
+
+// checkMonth обязательный месяц MM-YYYY, при успехе пишем его в dst
+func checkMonth(field, s string, dst *time.Time, v *domain.ValidationError) bool {
+	if s == "" {
+		v.Add(field, domain.CodeRequired, field+" is required")
+		return false
+	}
+	t, err := parseMonth(s)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start_date: %w", err)
+		v.Add(field, domain.CodeInvalidFormat, fmt.Sprintf("invalid %s: %v", field, err))
+		return false
 	}
+	*dst = t
+	return true
+}
 
-	var end *time.Time
-	if in.EndDate != nil && *in.EndDate != "" {
-		e, err := parseMonth(*in.EndDate)
-		if err != nil {
-			return nil, fmt.Errorf("invalid end_date: %w", err)
+// checkIntroPhases вводные периоды: не больше maxIntroPhases, каждый от месяца, цена не отрицательная
+func checkIntroPhases(in []dto.IntroPhase, v *domain.ValidationError) []domain.IntroPhase {
+	if len(in) == 0 {
+		return nil
+	}
+	if len(in) > maxIntroPhases {
+		v.Add("intro_phases", domain.CodeOutOfRange, fmt.Sprintf("too many intro_phases: max %d", maxIntroPhases))
+		return nil
+	}
+	res := make([]domain.IntroPhase, 0, len(in))
+	for i, ph := range in {
+		field := fmt.Sprintf("intro_phases[%d]", i)
+		if ph.Months <= 0 {
+			v.Add(field+".months", domain.CodeOutOfRange, "months must be > 0")
 		}
-		if e.Before(start) {
-			return nil, domain.ErrInvalidDates
+		if ph.Price < 0 {
+			v.Add(field+".price", domain.CodeOutOfRange, "price must be >= 0")
 		}
-		end = &e
+		res = append(res, domain.IntroPhase{Months: ph.Months, Price: ph.Price})
 	}
+	return res
+}
 
-	// собираем domain. Subscription и вызываем repo. Create
-	created, err := s.repo.Create(ctx, &domain.Subscription{
-		ServiceName: in.ServiceName, Price: in.Price, UserID: in.UserID,
-		StartDate: start, EndDate: end,
-	})
+// checkStarted price, currency, billing_period и start_date задают списания с start_date, их смена у уже начавшейся
+// подписки переписала бы прошлые месяцы в расчётах. Новая цена задаётся сменой цены, остальное — новой подпиской
+// Поля, по которым ошибка уже есть, не проверяем
+func checkStarted(cur, next *domain.Subscription, v *domain.ValidationError) {
+	if cur.StartDate.After(time.Now().UTC()) {
+		return
+	}
+	if next.Price != cur.Price && !v.Has("price") {
+		v.Add("price", domain.CodeInvalidValue,
+			"price of a started subscription cannot be changed, use POST /subscriptions/{id}/price-changes")
+	}
+	for _, f := range []struct {
+		name    string
+		changed bool
+	}{
+		{"currency", next.Currency != cur.Currency},
+		{"billing_period", next.BillingPeriod != cur.BillingPeriod},
+		{"start_date", !next.StartDate.Equal(cur.StartDate)},
+	} {
+		if f.changed && !v.Has(f.name) {
+			v.Add(f.name, domain.CodeInvalidValue, f.name+" of a started subscription cannot be changed, create a new subscription instead")
+		}
+	}
+}
+
+// subDate дата подписки из запроса: месяц MM-YYYY или день YYYY-MM-DD
+type subDate struct {
+	t   time.Time
+	day bool // задан день
+}
+
+// checkDate обязательная дата подписки MM-YYYY или YYYY-MM-DD, при успехе пишем её в dst
+func checkDate(field, s string, dst *subDate, v *domain.ValidationError) bool {
+	if s == "" {
+		v.Add(field, domain.CodeRequired, field+" is required")
+		return false
+	}
+	if t, err := parseMonth(s); err == nil {
+		*dst = subDate{t: t}
+		return true
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, time.UTC)
 	if err != nil {
-		return nil, err
+		v.Add(field, domain.CodeInvalidFormat, fmt.Sprintf("invalid %s: expected MM-YYYY or YYYY-MM-DD", field))
+		return false
+	}
+	*dst = subDate{t: t, day: true}
+	return true
+}
+
+// subDates приводим даты подписки к одной точности: дни, если хоть одна дата задана днём
+// С точностью до дня месяц начала — его первый день, месяц окончания — последний
+func subDates(start subDate, end *subDate) (time.Time, *time.Time, bool) {
+	day := start.day || (end != nil && end.day)
+	if end == nil {
+		return start.t, nil, day
+	}
+	e := end.t
+	if day && !end.day {
+		e = e.AddDate(0, 1, -1)
+	}
+	return start.t, &e, day
+}
+
+// storedDates даты сохранённой подписки в той точности, в которой она хранится
+func storedDates(s *domain.Subscription) (subDate, *subDate) {
+	start := subDate{t: s.StartDate, day: s.DayPrecision}
+	if s.EndDate == nil {
+		return start, nil
+	}
+	return start, &subDate{t: *s.EndDate, day: s.DayPrecision}
+}
+
+// checkDates конец не раньше начала, если обе даты разобрались
+func checkDates(start time.Time, end *time.Time, v *domain.ValidationError) {
+	if v.Has("start_date") || v.Has("end_date") {
+		return
+	}
+	if end != nil && end.Before(start) {
+		v.AddErr("end_date", domain.CodeOutOfRange, domain.ErrInvalidDates)
 	}
-	return toDTO(created), nil
 }
 
 // Get Вызываем repo. Get, преобразуем доменную модель в DTO
-func (s *Service) Get(ctx context.Context, id string) (*dto.SubscriptionResponse, error) {
-	out, err := s.repo.Get(ctx, id)
+// Мягко удалённую подписку отдаём только с includeDeleted
+func (s *Service) Get(ctx context.Context, id string, includeDeleted bool) (*dto.SubscriptionResponse, error) {
+	out, err := s.repo.Get(ctx, id, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -72,86 +309,441 @@ func (s *Service) Get(ctx context.Context, id string) (*dto.SubscriptionResponse
 
 // List Пробрасываем фильтры/лимиты в repo.List через repo.ListFilter.
 // Переводим []domain.Subscription в []dto.SubscriptionResponse.
-func (s *Service) List(ctx context.Context, q dto.ListQuery) ([]dto.SubscriptionResponse, error) {
-	items, err := s.repo.List(ctx, repo.ListFilter{
-		UserID: q.UserID, ServiceName: q.ServiceName, Limit: q.Limit, Offset: q.Offset,
-	})
+// С q.Cursor листаем keyset-пагинацией, иначе limit/offset, total считаем по тем же фильтрам
+func (s *Service) List(ctx context.Context, q dto.ListQuery) (*dto.SubscriptionPage, error) {
+	f, err := listFilter(q)
+	if err != nil {
+		return nil, err
+	}
+	f = f.Normalized()
+	items, hasMore, err := s.repo.List(ctx, f)
 	if err != nil {
 		return nil, err
 	}
-	res := make([]dto.SubscriptionResponse, 0, len(items))
+	total, err := s.repo.Count(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	page := &dto.SubscriptionPage{
+		Items:   make([]dto.SubscriptionResponse, 0, len(items)),
+		Total:   total,
+		Limit:   f.Limit,
+		HasMore: hasMore,
+	}
+	if q.Cursor == nil {
+		page.Offset = &f.Offset
+	}
 	for i := range items {
-		res = append(res, *toDTO(&items[i]))
+		page.Items = append(page.Items, *toDTO(&items[i]))
 	}
-	return res, nil
+	// Курсор задаёт позицию в порядке по умолчанию, для другой сортировки он бы перескочил или повторил строки
+	if hasMore && len(items) > 0 && f.Sort == nil {
+		last := items[len(items)-1]
+		c := encodeCursor(repo.ListCursor{StartDate: last.StartDate, ID: last.ID})
+		page.NextCursor = &c
+	}
+	return page, nil
 }
 
 // Update полная замена put, всё валидируем с нуля, формируем полную доменную модель и сохраняем
-func (s *Service) Update(ctx context.Context, id string, in dto.UpdateSubscriptionRequest) error {
-
-	if in.ServiceName == "" {
-		return fmt.Errorf("service_name is required")
+// price здесь — исходная цена с start_date, смены цены задаются через SchedulePriceChange
+// version — ожидаемая версия из If-Match, 0 — без проверки
+func (s *Service) Update(ctx context.Context, id string, version int, in dto.UpdateSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	cur, err := s.repo.Get(ctx, id, false)
+	if err != nil {
+		return nil, err
 	}
-	if in.Price <= 0 {
-		return domain.ErrInvalidPrice
+	// Начавшуюся подписку проверяем против той версии, которую видел клиент
+	if version != 0 && cur.Version != version {
+		return nil, domain.ErrConflict
 	}
-	if _, err := uuid.Parse(in.UserID); err != nil {
-		return fmt.Errorf("invalid user_id: %w", err)
+	var v domain.ValidationError
+	// nil = бессрочно, пустая строка — ошибка
+	if in.EndDate != nil && *in.EndDate == "" {
+		v.Add("end_date", domain.CodeInvalidFormat, "end_date must be null or 'MM-YYYY'")
 	}
+	sub := parseSubscription(dto.CreateSubscriptionRequest(in), &v)
+	checkStarted(cur, sub, &v)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	sub.ID, sub.Version = id, version
+	if err := s.repo.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+	return toDTO(sub), nil
+}
 
-	start, err := parseMonth(in.StartDate)
+// Patch частичное обновление по JSON Merge Patch
+// Применяем пришедшие поля к текущей записи, валидируем итог и пишем в БД только изменённые колонки
+// version — ожидаемая версия из If-Match, 0 — без проверки
+func (s *Service) Patch(ctx context.Context, id string, version int, in dto.PatchSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	cur, err := s.repo.Get(ctx, id, false)
 	if err != nil {
-		return fmt.Errorf("invalid start_date: %w", err)
+		return nil, err
+	}
+	// Валидируем против той версии, которую видел клиент
+	if version != 0 && cur.Version != version {
+		return nil, domain.ErrConflict
 	}
 
-	var end *time.Time
-	if in.EndDate != nil { // nil = бессрочно
-		if *in.EndDate == "" {
-			return fmt.Errorf("end_date must be null or 'YYYY-MM'")
+	var (
+		p = repo.SubscriptionPatch{Version: version}
+		v domain.ValidationError
+	)
+	if in.ServiceName.Set {
+		if in.ServiceName.Null || in.ServiceName.Value == "" {
+			v.Add("service_name", domain.CodeRequired, "service_name is required")
 		}
-		e, err := parseMonth(*in.EndDate)
-		if err != nil {
-			return fmt.Errorf("invalid end_date: %w", err)
+		p.ServiceName = &in.ServiceName.Value
+	}
+	if in.Price.Set {
+		if in.Price.Null || in.Price.Value <= 0 {
+			v.AddErr("price", domain.CodeOutOfRange, domain.ErrInvalidPrice)
+		}
+		p.Price = &in.Price.Value
+	}
+	if in.IntroPhases.Set {
+		// null и [] снимают вводные периоды
+		phases := checkIntroPhases(in.IntroPhases.Value, &v)
+		p.IntroPhases = &phases
+	}
+	if in.Currency.Set {
+		c, err := parseCurrency(in.Currency.Value)
+		if in.Currency.Null || err != nil {
+			v.AddErr("currency", domain.CodeInvalidValue, domain.ErrInvalidCurrency)
+		}
+		p.Currency = &c
+	}
+	if in.BillingPeriod.Set {
+		bp, err := parseBillingPeriod(in.BillingPeriod.Value)
+		if in.BillingPeriod.Null || err != nil {
+			v.AddErr("billing_period", domain.CodeInvalidValue, domain.ErrInvalidBillingPeriod)
+		}
+		p.BillingPeriod = &bp
+	}
+	if in.UserID.Set {
+		checkUserID(in.UserID.Value, &v)
+		p.UserID = &in.UserID.Value
+	}
+	start, end := storedDates(cur)
+	if in.StartDate.Set {
+		checkDate("start_date", in.StartDate.Value, &start, &v)
+	}
+	if in.EndDate.Set {
+		// null снимает дату окончания
+		end = nil
+		if !in.EndDate.Null {
+			var e subDate
+			if checkDate("end_date", in.EndDate.Value, &e, &v) {
+				end = &e
+			}
 		}
-		end = &e
+	}
+	startT, endT, day := subDates(start, end)
+	if in.StartDate.Set {
+		p.StartDate = &startT
+	}
+	// При смене точности end_date переводим в новую точность, даже если его не передали
+	if in.EndDate.Set || day != cur.DayPrecision {
+		p.SetEndDate, p.EndDate = true, endT
+	}
+	if day != cur.DayPrecision {
+		p.DayPrecision = &day
+	}
+	checkDates(startT, endT, &v)
+	// Итоговые поля, которые нельзя менять у начавшейся подписки
+	next := *cur
+	if p.Price != nil {
+		next.Price = *p.Price
+	}
+	if p.Currency != nil {
+		next.Currency = *p.Currency
+	}
+	if p.BillingPeriod != nil {
+		next.BillingPeriod = *p.BillingPeriod
+	}
+	next.StartDate = startT
+	checkStarted(cur, &next, &v)
+	if err := v.Err(); err != nil {
+		return nil, err
 	}
 
-	if end != nil && end.Before(start) {
-		return domain.ErrInvalidDates
+	out, err := s.repo.Patch(ctx, id, p)
+	if err != nil {
+		return nil, err
 	}
+	return toDTO(out), nil
+}
 
-	return s.repo.Update(ctx, &domain.Subscription{
-		ID:          id,
-		ServiceName: in.ServiceName,
-		Price:       in.Price,
-		UserID:      in.UserID,
-		StartDate:   start,
-		EndDate:     end,
-	})
+// Delete Мягкое удаление через repo.Delete, version — ожидаемая версия из If-Match, 0 — без проверки
+func (s *Service) Delete(ctx context.Context, id string, version int) error {
+	return s.repo.Delete(ctx, id, version)
+}
+
+// Cancel Завершаем подписку: end_date — последний оплачиваемый месяц или день
+// version — ожидаемая версия из If-Match, 0 — без проверки
+func (s *Service) Cancel(ctx context.Context, id string, version int, in dto.CancelRequest) (*dto.SubscriptionResponse, error) {
+	cur, err := s.repo.Get(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && cur.Version != version {
+		return nil, domain.ErrConflict
+	}
+	var (
+		v   domain.ValidationError
+		end subDate
+	)
+	start, _ := storedDates(cur)
+	checkDate("end_date", in.EndDate, &end, &v)
+	startT, endT, day := subDates(start, &end)
+	checkDates(startT, endT, &v)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	p := repo.SubscriptionPatch{SetEndDate: true, EndDate: endT, Version: version}
+	if day != cur.DayPrecision {
+		p.DayPrecision = &day
+	}
+	out, err := s.repo.Patch(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+	return toDTO(out), nil
+}
+
+// Pause Приостанавливаем подписку на месяцы from..to, без to — до возобновления
+func (s *Service) Pause(ctx context.Context, id string, in dto.PauseRequest) (*dto.PauseResponse, error) {
+	sub, err := s.repo.Get(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	var v domain.ValidationError
+	p := &domain.Pause{SubscriptionID: sub.ID}
+	checkMonth("from", in.From, &p.From, &v)
+	if in.To != nil {
+		var to time.Time
+		if checkMonth("to", *in.To, &to, &v) {
+			p.To = &to
+		}
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(sub); err != nil {
+		return nil, err
+	}
+	out, err := s.repo.CreatePause(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	res := pauseToDTO(out)
+	return &res, nil
+}
+
+// Resume Возобновляем подписку с месяца from, пауза, в которую он попадает, заканчивается месяцем раньше
+func (s *Service) Resume(ctx context.Context, id string, in dto.ResumeRequest) error {
+	var (
+		v    domain.ValidationError
+		from time.Time
+	)
+	checkMonth("from", in.From, &from, &v)
+	if err := v.Err(); err != nil {
+		return err
+	}
+	return s.repo.ResumePause(ctx, id, from)
+}
+
+// DeletePause Отменяем паузу целиком, например ещё не начавшуюся
+func (s *Service) DeletePause(ctx context.Context, id, pauseID string) error {
+	return s.repo.DeletePause(ctx, id, pauseID)
+}
+
+// ListPauses Паузы подписки по возрастанию месяца начала
+func (s *Service) ListPauses(ctx context.Context, id string) ([]dto.PauseResponse, error) {
+	// Проверяем, что подписка есть, чтобы отдать 404, а не пустой список
+	if _, err := s.repo.Get(ctx, id, false); err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListPauses(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.PauseResponse, 0, len(items))
+	for i := range items {
+		res = append(res, pauseToDTO(&items[i]))
+	}
+	return res, nil
+}
+
+// Restore Возвращаем мягко удалённую подписку, version — ожидаемая версия из If-Match
+func (s *Service) Restore(ctx context.Context, id string, version int) (*dto.SubscriptionResponse, error) {
+	out, err := s.repo.Restore(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return toDTO(out), nil
+}
+
+// PurgeDeleted Окончательно удаляем подписки, мягко удалённые больше retention назад
+func (s *Service) PurgeDeleted(ctx context.Context, retention time.Duration) (dto.PurgeResponse, error) {
+	before := time.Now().Add(-retention).UTC()
+	n, err := s.repo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return dto.PurgeResponse{}, err
+	}
+	return dto.PurgeResponse{Purged: n, DeletedBefore: before.Format(time.RFC3339)}, nil
+}
+
+// SchedulePriceChange Новая цена с месяца effective_from, прошлые месяцы считаются по старой цене
+func (s *Service) SchedulePriceChange(ctx context.Context, id string, in dto.SchedulePriceChangeRequest) (*dto.PriceChangeResponse, error) {
+	sub, err := s.repo.Get(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseMonth(in.EffectiveFrom)
+	if err != nil {
+		return nil, domain.Invalid("effective_from", domain.CodeInvalidFormat, fmt.Sprintf("invalid effective_from: %v", err))
+	}
+	pc := &domain.PriceChange{SubscriptionID: sub.ID, EffectiveFrom: from, Price: in.Price}
+	if err := pc.Validate(sub); err != nil {
+		return nil, err
+	}
+	out, err := s.repo.SchedulePriceChange(ctx, pc)
+	if err != nil {
+		return nil, err
+	}
+	res := priceChangeToDTO(out)
+	return &res, nil
+}
+
+// ListPriceChanges История смен цены подписки
+func (s *Service) ListPriceChanges(ctx context.Context, id string) ([]dto.PriceChangeResponse, error) {
+	// Проверяем, что подписка есть, чтобы отдать 404, а не пустой список
+	if _, err := s.repo.Get(ctx, id, false); err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListPriceChanges(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.PriceChangeResponse, 0, len(items))
+	for i := range items {
+		res = append(res, priceChangeToDTO(&items[i]))
+	}
+	return res, nil
 }
 
-// Delete Выполняем repo.Delete
-func (s *Service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+// History Журнал изменений подписки, доступен и после её удаления
+// Подписки, созданные до появления журнала, могут не иметь записей — для них пустой список, 404 только если подписки нет
+func (s *Service) History(ctx context.Context, id string) ([]dto.SubscriptionEventResponse, error) {
+	items, err := s.repo.ListEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		if _, err := s.repo.Get(ctx, id, true); err != nil {
+			return nil, err
+		}
+	}
+	res := make([]dto.SubscriptionEventResponse, 0, len(items))
+	for _, e := range items {
+		res = append(res, dto.SubscriptionEventResponse{
+			ID:             e.ID,
+			SubscriptionID: e.SubscriptionID,
+			Action:         string(e.Action),
+			Before:         e.Before,
+			After:          e.After,
+			Actor:          e.Actor,
+			RequestID:      e.RequestID,
+			CreatedAt:      e.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return res, nil
 }
 
 // TotalCost  Парсим from и to как месяцы через parseMonth
-// Выполняем repo.CalcTotal
+// Выполняем repo.CalcTotal, суммы пересчитываются в q.Currency
 func (s *Service) TotalCost(ctx context.Context, q dto.TotalCostQuery) (dto.TotalCostResponse, error) {
 	from, err := parseMonth(q.From)
 	if err != nil {
-		return dto.TotalCostResponse{}, fmt.Errorf("invalid from: %w", err)
+		return dto.TotalCostResponse{}, domain.Invalid("from", domain.CodeInvalidFormat, fmt.Sprintf("invalid from: %v", err))
 	}
 	to, err := parseMonth(q.To)
 	if err != nil {
-		return dto.TotalCostResponse{}, fmt.Errorf("invalid to: %w", err)
+		return dto.TotalCostResponse{}, domain.Invalid("to", domain.CodeInvalidFormat, fmt.Sprintf("invalid to: %v", err))
 	}
-	total, months, err := s.repo.CalcTotal(ctx, from, to, q.UserID, q.ServiceName)
+	currency, err := parseCurrency(q.Currency)
+	if err != nil {
+		return dto.TotalCostResponse{}, err
+	}
+	total, months, err := s.repo.CalcTotal(ctx, repo.CostFilter{
+		From: from, To: to, UserID: q.UserID, ServiceName: q.ServiceName, Currency: currency,
+		IncludeDeleted: q.IncludeDeleted, Rounding: s.rounding,
+	})
 	if err != nil {
 		return dto.TotalCostResponse{}, err
 	}
 	// Возвращаем DTO
-	return dto.TotalCostResponse{Total: total, Currency: "RUB", MonthsCounted: months}, nil
+	return dto.TotalCostResponse{Total: total, Currency: currency, MonthsCounted: months}, nil
+}
+
+// CostBreakdown Помесячная разбивка суммы за период, опционально с группировкой по сервису и/или пользователю
+func (s *Service) CostBreakdown(ctx context.Context, q dto.CostBreakdownQuery) (dto.CostBreakdownResponse, error) {
+	f, g, err := s.breakdownParams(q)
+	if err != nil {
+		return dto.CostBreakdownResponse{}, err
+	}
+	rows, err := s.repo.CalcBreakdown(ctx, f, g)
+	if err != nil {
+		return dto.CostBreakdownResponse{}, err
+	}
+	// Без группировки отдаём каждый месяц периода, чтобы на графике не было дыр
+	if !g.ByService && !g.ByUser {
+		rows = fillMonths(rows, f.From, f.To)
+	}
+
+	res := dto.CostBreakdownResponse{Currency: f.Currency, Items: make([]dto.CostBreakdownItem, 0, len(rows))}
+	for i := range rows {
+		res.Total += rows[i].Total
+		res.Items = append(res.Items, monthlyCostToDTO(&rows[i]))
+	}
+	return res, nil
+}
+
+// breakdownParams разбираем период, валюту и группировку разбивки
+func (s *Service) breakdownParams(q dto.CostBreakdownQuery) (repo.CostFilter, repo.BreakdownGroup, error) {
+	var g repo.BreakdownGroup
+	from, err := parseMonth(q.From)
+	if err != nil {
+		return repo.CostFilter{}, g, domain.Invalid("from", domain.CodeInvalidFormat, fmt.Sprintf("invalid from: %v", err))
+	}
+	to, err := parseMonth(q.To)
+	if err != nil {
+		return repo.CostFilter{}, g, domain.Invalid("to", domain.CodeInvalidFormat, fmt.Sprintf("invalid to: %v", err))
+	}
+	if to.Before(from) {
+		return repo.CostFilter{}, g, domain.Invalid("to", domain.CodeOutOfRange, "to must not be before from")
+	}
+	currency, err := parseCurrency(q.Currency)
+	if err != nil {
+		return repo.CostFilter{}, g, err
+	}
+	for _, v := range q.GroupBy {
+		switch v {
+		case "service_name":
+			g.ByService = true
+		case "user_id":
+			g.ByUser = true
+		default:
+			return repo.CostFilter{}, g, domain.Invalid("group_by", domain.CodeInvalidValue, fmt.Sprintf("invalid group_by: %q", v))
+		}
+	}
+	return repo.CostFilter{
+		From: from, To: to, UserID: q.UserID, ServiceName: q.ServiceName, Currency: currency,
+		IncludeDeleted: q.IncludeDeleted, Rounding: s.rounding,
+	}, g, nil
 }
 
 // Вспомогательные функции
@@ -164,19 +756,295 @@ func parseMonth(s string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("expected MM-YYYY")
 }
 
+// Пустой период считаем помесячной оплатой
+func parseBillingPeriod(s string) (domain.BillingPeriod, error) {
+	if s == "" {
+		return domain.BillingMonthly, nil
+	}
+	p := domain.BillingPeriod(s)
+	if !p.Valid() {
+		return "", domain.ErrInvalidBillingPeriod
+	}
+	return p, nil
+}
+
+// Пустую валюту считаем рублями, код приводим к верхнему регистру
+func parseCurrency(s string) (string, error) {
+	if s == "" {
+		return domain.BaseCurrency, nil
+	}
+	c := strings.ToUpper(s)
+	if !domain.ValidCurrency(c) {
+		return "", domain.ErrInvalidCurrency
+	}
+	return c, nil
+}
+
+// listFilter разбираем и валидируем параметры списка, на неверное значение отдаём ошибку, а не дефолт
+func listFilter(q dto.ListQuery) (repo.ListFilter, error) {
+	f := repo.ListFilter{UserID: q.UserID, ServiceName: q.ServiceName, IncludeDeleted: q.IncludeDeleted}
+	var err error
+	if q.UserID != nil {
+		if _, err := uuid.Parse(*q.UserID); err != nil {
+			return f, domain.Invalid("user_id", domain.CodeInvalidFormat, fmt.Sprintf("invalid user_id: %v", err))
+		}
+	}
+	if f.Limit, err = parseOptInt(q.Limit, 1); err != nil {
+		return f, domain.Invalid("limit", domain.CodeInvalidFormat, fmt.Sprintf("invalid limit: %v", err))
+	}
+	if f.Offset, err = parseOptInt(q.Offset, 0); err != nil {
+		return f, domain.Invalid("offset", domain.CodeInvalidFormat, fmt.Sprintf("invalid offset: %v", err))
+	}
+	if q.PriceMin != "" {
+		v, err := parseOptInt(q.PriceMin, 0)
+		if err != nil {
+			return f, domain.Invalid("price_min", domain.CodeInvalidFormat, fmt.Sprintf("invalid price_min: %v", err))
+		}
+		f.PriceMin = &v
+	}
+	if q.PriceMax != "" {
+		v, err := parseOptInt(q.PriceMax, 0)
+		if err != nil {
+			return f, domain.Invalid("price_max", domain.CodeInvalidFormat, fmt.Sprintf("invalid price_max: %v", err))
+		}
+		f.PriceMax = &v
+	}
+	if f.PriceMin != nil && f.PriceMax != nil && *f.PriceMin > *f.PriceMax {
+		return f, domain.Invalid("price_max", domain.CodeOutOfRange, "price_min must not be greater than price_max")
+	}
+	if f.ActiveAt, err = parseOptMonth(q.ActiveAt); err != nil {
+		return f, domain.Invalid("active_at", domain.CodeInvalidFormat, fmt.Sprintf("invalid active_at: %v", err))
+	}
+	if f.StartFrom, err = parseOptMonth(q.StartFrom); err != nil {
+		return f, domain.Invalid("start_from", domain.CodeInvalidFormat, fmt.Sprintf("invalid start_from: %v", err))
+	}
+	if f.StartTo, err = parseOptMonth(q.StartTo); err != nil {
+		return f, domain.Invalid("start_to", domain.CodeInvalidFormat, fmt.Sprintf("invalid start_to: %v", err))
+	}
+	if f.StartFrom != nil && f.StartTo != nil && f.StartTo.Before(*f.StartFrom) {
+		return f, domain.Invalid("start_to", domain.CodeOutOfRange, "start_to must not be before start_from")
+	}
+	for _, p := range []struct {
+		name  string
+		value string
+		dst   **time.Time
+		to    bool
+	}{
+		{"created_from", q.CreatedFrom, &f.CreatedFrom, false},
+		{"created_to", q.CreatedTo, &f.CreatedTo, true},
+		{"updated_from", q.UpdatedFrom, &f.UpdatedFrom, false},
+		{"updated_to", q.UpdatedTo, &f.UpdatedTo, true},
+	} {
+		if *p.dst, err = parseOptTime(p.value, p.to); err != nil {
+			return f, domain.Invalid(p.name, domain.CodeInvalidFormat, fmt.Sprintf("invalid %s: %v", p.name, err))
+		}
+	}
+	if q.Status != "" {
+		st := repo.ListStatus(q.Status)
+		switch st {
+		case repo.StatusActive, repo.StatusEnded, repo.StatusOpenEnded:
+			f.Status = &st
+		default:
+			return f, domain.Invalid("status", domain.CodeInvalidValue, "invalid status: expected active, ended or open-ended")
+		}
+	}
+	if q.Sort != "" {
+		srt := &repo.ListSort{Field: strings.TrimPrefix(q.Sort, "-"), Desc: strings.HasPrefix(q.Sort, "-")}
+		switch srt.Field {
+		case repo.SortPrice, repo.SortStartDate, repo.SortEndDate, repo.SortServiceName, repo.SortCreatedAt, repo.SortUpdatedAt:
+			f.Sort = srt
+		default:
+			return f, domain.Invalid("sort", domain.CodeInvalidValue,
+				"invalid sort: expected price, start_date, end_date, service_name, created_at or updated_at, optionally prefixed with -")
+		}
+	}
+	if q.Cursor != nil && *q.Cursor != "" {
+		after, err := decodeCursor(*q.Cursor)
+		if err != nil {
+			return f, err
+		}
+		f.After = after
+	}
+	// keyset-курсор построен на порядке start_date desc, id desc
+	if q.Cursor != nil && f.Sort != nil {
+		return f, domain.Invalid("sort", domain.CodeInvalidValue, "cursor pagination supports only the default sort")
+	}
+	return f, nil
+}
+
+// Пустая строка — 0, иначе целое не меньше min
+func parseOptInt(s string, min int) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("expected integer")
+	}
+	if n < min {
+		return 0, fmt.Errorf("must be >= %d", min)
+	}
+	return n, nil
+}
+
+// Пустая строка — nil, иначе месяц MM-YYYY
+func parseOptMonth(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := parseMonth(s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Пустая строка — nil, иначе момент времени RFC 3339 или дата YYYY-MM-DD (UTC)
+// Дата в верхней границе (to) включает весь день, поэтому сдвигаем её на начало следующего
+func parseOptTime(s string, to bool) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD")
+	}
+	if to {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// cursorPayload содержимое курсора, клиенту отдаём его как непрозрачную base64-строку
+type cursorPayload struct {
+	StartDate string `json:"s"`
+	ID        string `json:"id"`
+}
+
+// Кодируем позицию последней записи страницы
+func encodeCursor(c repo.ListCursor) string {
+	b, _ := json.Marshal(cursorPayload{StartDate: c.StartDate.Format(time.DateOnly), ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Разбираем курсор, любые ошибки формата отдаём как ErrInvalidCursor
+func decodeCursor(s string) (*repo.ListCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	start, err := time.ParseInLocation(time.DateOnly, p.StartDate, time.UTC)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(p.ID); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &repo.ListCursor{StartDate: start, ID: p.ID}, nil
+}
+
+// monthlyCostToDTO маппим строку разбивки в ответ
+func monthlyCostToDTO(c *domain.MonthlyCost) dto.CostBreakdownItem {
+	return dto.CostBreakdownItem{
+		Month:         c.Month.Format("01-2006"),
+		ServiceName:   c.ServiceName,
+		UserID:        c.UserID,
+		Total:         c.Total,
+		Subscriptions: c.Subscriptions,
+	}
+}
+
 // toDTO маппим доменную модель в ответ и форматируем месяцы
 func toDTO(s *domain.Subscription) *dto.SubscriptionResponse {
-	var end *string
+	var end, deleted *string
 	if s.EndDate != nil {
-		v := s.EndDate.Format("01-2006")
+		v := formatDate(*s.EndDate, s.DayPrecision)
 		end = &v
 	}
+	if s.DeletedAt != nil {
+		v := s.DeletedAt.UTC().Format(time.RFC3339)
+		deleted = &v
+	}
 	return &dto.SubscriptionResponse{
-		ID:          s.ID,
-		ServiceName: s.ServiceName,
-		Price:       s.Price,
-		UserID:      s.UserID,
-		StartDate:   s.StartDate.Format("01-2006"),
-		EndDate:     end,
+		ID:            s.ID,
+		ServiceName:   s.ServiceName,
+		Price:         s.Price,
+		IntroPhases:   introToDTO(s.IntroPhases),
+		Currency:      s.Currency,
+		BillingPeriod: string(s.BillingPeriod),
+		UserID:        s.UserID,
+		StartDate:     formatDate(s.StartDate, s.DayPrecision),
+		EndDate:       end,
+		DayPrecision:  s.DayPrecision,
+		Version:       s.Version,
+		CreatedAt:     s.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     s.UpdatedAt.UTC().Format(time.RFC3339),
+		CreatedBy:     s.CreatedBy,
+		UpdatedBy:     s.UpdatedBy,
+		DeletedAt:     deleted,
+	}
+}
+
+// fillMonths дополняет разбивку без группировки нулевыми месяцами в пределах [from, to]
+func fillMonths(rows []domain.MonthlyCost, from, to time.Time) []domain.MonthlyCost {
+	byMonth := make(map[time.Time]domain.MonthlyCost, len(rows))
+	for _, r := range rows {
+		byMonth[r.Month] = r
+	}
+	res := make([]domain.MonthlyCost, 0, len(rows))
+	for m := from; !m.After(to); m = m.AddDate(0, 1, 0) {
+		if r, ok := byMonth[m]; ok {
+			res = append(res, r)
+			continue
+		}
+		res = append(res, domain.MonthlyCost{Month: m})
+	}
+	return res
+}
+
+// introToDTO вводные периоды для ответа, пустые не отдаём
+func introToDTO(ph []domain.IntroPhase) []dto.IntroPhase {
+	if len(ph) == 0 {
+		return nil
+	}
+	res := make([]dto.IntroPhase, 0, len(ph))
+	for _, p := range ph {
+		res = append(res, dto.IntroPhase{Months: p.Months, Price: p.Price})
+	}
+	return res
+}
+
+// formatDate дата подписки в формате, в котором её задают: YYYY-MM-DD с точностью до дня, иначе MM-YYYY
+func formatDate(t time.Time, day bool) string {
+	if day {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format("01-2006")
+}
+
+// pauseToDTO маппим паузу в ответ, месяцы в MM-YYYY, без to — пауза до возобновления
+func pauseToDTO(p *domain.Pause) dto.PauseResponse {
+	res := dto.PauseResponse{ID: p.ID, SubscriptionID: p.SubscriptionID, From: p.From.Format("01-2006")}
+	if p.To != nil {
+		to := p.To.Format("01-2006")
+		res.To = &to
+	}
+	return res
+}
+
+// priceChangeToDTO маппим смену цены в ответ
+func priceChangeToDTO(p *domain.PriceChange) dto.PriceChangeResponse {
+	return dto.PriceChangeResponse{
+		ID:             p.ID,
+		SubscriptionID: p.SubscriptionID,
+		EffectiveFrom:  p.EffectiveFrom.Format("01-2006"),
+		Price:          p.Price,
 	}
 }
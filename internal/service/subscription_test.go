@@ -0,0 +1,645 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/repo"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCheckDate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want subDate
+		code string // пусто — дата разобралась
+	}{
+		{name: "month", in: "07-2025", want: subDate{t: date(2025, 7, 1)}},
+		{name: "day", in: "2025-07-15", want: subDate{t: date(2025, 7, 15), day: true}},
+		{name: "leap day", in: "2024-02-29", want: subDate{t: date(2024, 2, 29), day: true}},
+		{name: "empty", in: "", code: domain.CodeRequired},
+		{name: "bad month", in: "13-2025", code: domain.CodeInvalidFormat},
+		{name: "bad day", in: "2025-02-30", code: domain.CodeInvalidFormat},
+		{name: "garbage", in: "July 2025", code: domain.CodeInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				v   domain.ValidationError
+				got subDate
+			)
+			ok := checkDate("start_date", tt.in, &got, &v)
+			if tt.code != "" {
+				if ok || len(v.Fields) != 1 || v.Fields[0].Field != "start_date" || v.Fields[0].Code != tt.code {
+					t.Fatalf("checkDate(%q) = %v, errors %+v; want code %s", tt.in, ok, v.Fields, tt.code)
+				}
+				return
+			}
+			if !ok || v.Err() != nil {
+				t.Fatalf("checkDate(%q) failed: %v", tt.in, v.Err())
+			}
+			if !got.t.Equal(tt.want.t) || got.day != tt.want.day {
+				t.Errorf("checkDate(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     subDate
+		end       *subDate
+		wantStart time.Time
+		wantEnd   *time.Time
+		wantDay   bool
+	}{
+		{
+			name:      "month without end",
+			start:     subDate{t: date(2025, 1, 1)},
+			wantStart: date(2025, 1, 1),
+		},
+		{
+			name:      "both months",
+			start:     subDate{t: date(2025, 1, 1)},
+			end:       &subDate{t: date(2025, 6, 1)},
+			wantStart: date(2025, 1, 1),
+			wantEnd:   ptr(date(2025, 6, 1)),
+		},
+		{
+			name:      "day start expands month end to last day",
+			start:     subDate{t: date(2025, 1, 10), day: true},
+			end:       &subDate{t: date(2025, 6, 1)},
+			wantStart: date(2025, 1, 10),
+			wantEnd:   ptr(date(2025, 6, 30)),
+			wantDay:   true,
+		},
+		{
+			name:      "month end in leap february",
+			start:     subDate{t: date(2024, 1, 10), day: true},
+			end:       &subDate{t: date(2024, 2, 1)},
+			wantStart: date(2024, 1, 10),
+			wantEnd:   ptr(date(2024, 2, 29)),
+			wantDay:   true,
+		},
+		{
+			name:      "day end keeps month start on the first",
+			start:     subDate{t: date(2025, 1, 1)},
+			end:       &subDate{t: date(2025, 6, 15), day: true},
+			wantStart: date(2025, 1, 1),
+			wantEnd:   ptr(date(2025, 6, 15)),
+			wantDay:   true,
+		},
+		{
+			name:      "day start without end",
+			start:     subDate{t: date(2025, 1, 10), day: true},
+			wantStart: date(2025, 1, 10),
+			wantDay:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, day := subDates(tt.start, tt.end)
+			if !start.Equal(tt.wantStart) || day != tt.wantDay || !equalTime(end, tt.wantEnd) {
+				t.Errorf("subDates() = %v, %v, %v; want %v, %v, %v", start, fmtTime(end), day, tt.wantStart, fmtTime(tt.wantEnd), tt.wantDay)
+			}
+		})
+	}
+}
+
+func TestStoredDates(t *testing.T) {
+	end := date(2025, 6, 15)
+	start, e := storedDates(&domain.Subscription{StartDate: date(2025, 1, 10), EndDate: &end, DayPrecision: true})
+	if !start.day || e == nil || !e.day || !e.t.Equal(end) {
+		t.Errorf("day precision: got %+v, %+v", start, e)
+	}
+	start, e = storedDates(&domain.Subscription{StartDate: date(2025, 1, 1)})
+	if start.day || e != nil {
+		t.Errorf("month precision without end: got %+v, %+v", start, e)
+	}
+}
+
+// Смена точности так, как её делают Patch и Cancel: сохранённые даты, поверх — пришедшие
+func TestPrecisionSwitch(t *testing.T) {
+	monthEnd := date(2025, 6, 1)
+	dayEnd := date(2025, 6, 15)
+	monthSub := &domain.Subscription{StartDate: date(2025, 1, 1), EndDate: &monthEnd}
+	daySub := &domain.Subscription{StartDate: date(2025, 1, 10), EndDate: &dayEnd, DayPrecision: true}
+
+	tests := []struct {
+		name        string
+		cur         *domain.Subscription
+		start, end  string // пусто — поле не передано
+		wantStart   time.Time
+		wantEnd     time.Time
+		wantDay     bool
+		wantInvalid bool
+	}{
+		{name: "month to day by end", cur: monthSub, end: "2025-06-15",
+			wantStart: date(2025, 1, 1), wantEnd: date(2025, 6, 15), wantDay: true},
+		{name: "month to day by start", cur: monthSub, start: "2025-01-20",
+			wantStart: date(2025, 1, 20), wantEnd: date(2025, 6, 30), wantDay: true},
+		{name: "day to month by both", cur: daySub, start: "01-2025", end: "06-2025",
+			wantStart: date(2025, 1, 1), wantEnd: date(2025, 6, 1)},
+		{name: "day stays with month end", cur: daySub, end: "08-2025",
+			wantStart: date(2025, 1, 10), wantEnd: date(2025, 8, 31), wantDay: true},
+		{name: "day stays with month start", cur: daySub, start: "01-2025",
+			wantStart: date(2025, 1, 1), wantEnd: date(2025, 6, 15), wantDay: true},
+		{name: "nothing changes", cur: monthSub,
+			wantStart: date(2025, 1, 1), wantEnd: date(2025, 6, 1)},
+		{name: "end before start", cur: daySub, end: "2025-01-09", wantInvalid: true},
+		{name: "month end before day start", cur: daySub, end: "12-2024", wantInvalid: true},
+		{name: "end in start month", cur: daySub, end: "01-2025",
+			wantStart: date(2025, 1, 10), wantEnd: date(2025, 1, 31), wantDay: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v domain.ValidationError
+			start, end := storedDates(tt.cur)
+			if tt.start != "" {
+				checkDate("start_date", tt.start, &start, &v)
+			}
+			if tt.end != "" {
+				var e subDate
+				if checkDate("end_date", tt.end, &e, &v) {
+					end = &e
+				}
+			}
+			startT, endT, day := subDates(start, end)
+			checkDates(startT, endT, &v)
+			if tt.wantInvalid {
+				if !errors.Is(v.Err(), domain.ErrInvalidDates) || !v.Has("end_date") {
+					t.Fatalf("want ErrInvalidDates on end_date, got %v", v.Err())
+				}
+				return
+			}
+			if err := v.Err(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !startT.Equal(tt.wantStart) || endT == nil || !endT.Equal(tt.wantEnd) || day != tt.wantDay {
+				t.Errorf("got %v, %v, %v; want %v, %v, %v", startT, fmtTime(endT), day, tt.wantStart, tt.wantEnd, tt.wantDay)
+			}
+		})
+	}
+}
+
+func TestCheckDates(t *testing.T) {
+	start := date(2025, 3, 1)
+	tests := []struct {
+		name    string
+		end     *time.Time
+		prior   string // поле, по которому ошибка уже есть
+		wantErr bool
+	}{
+		{name: "open ended"},
+		{name: "same month", end: ptr(start)},
+		{name: "after start", end: ptr(date(2025, 4, 1))},
+		{name: "before start", end: ptr(date(2025, 2, 1)), wantErr: true},
+		{name: "skipped when start invalid", end: ptr(date(2025, 2, 1)), prior: "start_date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v domain.ValidationError
+			if tt.prior != "" {
+				v.Add(tt.prior, domain.CodeInvalidFormat, "bad")
+			}
+			checkDates(start, tt.end, &v)
+			if got := v.Has("end_date"); got != tt.wantErr {
+				t.Errorf("end_date error = %v, want %v (%v)", got, tt.wantErr, v.Err())
+			}
+		})
+	}
+}
+
+func TestFillMonths(t *testing.T) {
+	row := func(m time.Month, total int64) domain.MonthlyCost {
+		return domain.MonthlyCost{Month: date(2025, m, 1), Total: total, Subscriptions: 1}
+	}
+	tests := []struct {
+		name     string
+		rows     []domain.MonthlyCost
+		from, to time.Time
+		want     []domain.MonthlyCost
+	}{
+		{name: "no rows", from: date(2025, 1, 1), to: date(2025, 3, 1),
+			want: []domain.MonthlyCost{{Month: date(2025, 1, 1)}, {Month: date(2025, 2, 1)}, {Month: date(2025, 3, 1)}}},
+		{name: "gaps filled", rows: []domain.MonthlyCost{row(1, 100), row(3, 300)}, from: date(2025, 1, 1), to: date(2025, 4, 1),
+			want: []domain.MonthlyCost{row(1, 100), {Month: date(2025, 2, 1)}, row(3, 300), {Month: date(2025, 4, 1)}}},
+		{name: "full", rows: []domain.MonthlyCost{row(1, 100), row(2, 200)}, from: date(2025, 1, 1), to: date(2025, 2, 1),
+			want: []domain.MonthlyCost{row(1, 100), row(2, 200)}},
+		{name: "single month", from: date(2025, 5, 1), to: date(2025, 5, 1), want: []domain.MonthlyCost{{Month: date(2025, 5, 1)}}},
+		{name: "across year", rows: []domain.MonthlyCost{{Month: date(2026, 1, 1), Total: 50}}, from: date(2025, 12, 1), to: date(2026, 1, 1),
+			want: []domain.MonthlyCost{{Month: date(2025, 12, 1)}, {Month: date(2026, 1, 1), Total: 50}}},
+		{name: "rows outside range dropped", rows: []domain.MonthlyCost{row(1, 100), row(6, 600)}, from: date(2025, 2, 1), to: date(2025, 3, 1),
+			want: []domain.MonthlyCost{{Month: date(2025, 2, 1)}, {Month: date(2025, 3, 1)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fillMonths(tt.rows, tt.from, tt.to)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("fillMonths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	in := repo.ListCursor{StartDate: date(2025, 7, 1), ID: "60601fee-2bf1-4721-ae6f-7636e79a0cba"}
+	out, err := decodeCursor(encodeCursor(in))
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if !out.StartDate.Equal(in.StartDate) || out.ID != in.ID {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestDecodeCursorMalformed(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "empty", in: ""},
+		{name: "not base64", in: "!!!"},
+		{name: "padded base64", in: base64.URLEncoding.EncodeToString([]byte(`{"s":"2025-07-01","id":"60601fee-2bf1-4721-ae6f-7636e79a0cba"}`))},
+		{name: "not json", in: enc("start=2025-07-01")},
+		{name: "bad date", in: enc(`{"s":"07-2025","id":"60601fee-2bf1-4721-ae6f-7636e79a0cba"}`)},
+		{name: "missing date", in: enc(`{"id":"60601fee-2bf1-4721-ae6f-7636e79a0cba"}`)},
+		{name: "bad id", in: enc(`{"s":"2025-07-01","id":"42"}`)},
+		{name: "wrong types", in: enc(`{"s":20250701,"id":true}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := decodeCursor(tt.in); !errors.Is(err, domain.ErrInvalidCursor) {
+				t.Errorf("decodeCursor(%q) = %+v, %v; want ErrInvalidCursor", tt.in, c, err)
+			}
+		})
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	const user = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	tests := []struct {
+		name  string
+		q     dto.ListQuery
+		check func(t *testing.T, f repo.ListFilter)
+		field string // поле ошибки, пусто — параметры разобрались
+		code  string
+	}{
+		{
+			name: "empty",
+			check: func(t *testing.T, f repo.ListFilter) {
+				if f.Limit != 0 || f.Offset != 0 || f.PriceMin != nil || f.Sort != nil || f.After != nil || f.Status != nil {
+					t.Errorf("filter = %+v, want zero", f)
+				}
+			},
+		},
+		{
+			name: "all filters",
+			q: dto.ListQuery{UserID: ptr(user), PriceMin: "100", PriceMax: "100", ActiveAt: "07-2025",
+				StartFrom: "01-2025", StartTo: "12-2025", CreatedTo: "2025-07-31", Status: "open-ended",
+				Sort: "-price", Limit: "20", Offset: "40"},
+			check: func(t *testing.T, f repo.ListFilter) {
+				if *f.PriceMin != 100 || *f.PriceMax != 100 || !f.ActiveAt.Equal(date(2025, 7, 1)) ||
+					!f.StartFrom.Equal(date(2025, 1, 1)) || !f.StartTo.Equal(date(2025, 12, 1)) ||
+					*f.Status != repo.StatusOpenEnded || f.Limit != 20 || f.Offset != 40 {
+					t.Errorf("filter = %+v", f)
+				}
+				if !f.CreatedTo.Equal(date(2025, 8, 1)) {
+					t.Errorf("created_to = %v, want next day", f.CreatedTo)
+				}
+				if f.Sort == nil || f.Sort.Field != repo.SortPrice || !f.Sort.Desc {
+					t.Errorf("sort = %+v", f.Sort)
+				}
+			},
+		},
+		{
+			name: "first keyset page",
+			q:    dto.ListQuery{Cursor: ptr("")},
+			check: func(t *testing.T, f repo.ListFilter) {
+				if f.After != nil {
+					t.Errorf("after = %+v, want nil", f.After)
+				}
+			},
+		},
+		{
+			name: "cursor",
+			q:    dto.ListQuery{Cursor: ptr(encodeCursor(repo.ListCursor{StartDate: date(2025, 7, 1), ID: user}))},
+			check: func(t *testing.T, f repo.ListFilter) {
+				if f.After == nil || f.After.ID != user {
+					t.Errorf("after = %+v", f.After)
+				}
+			},
+		},
+		{name: "bad user", q: dto.ListQuery{UserID: ptr("42")}, field: "user_id", code: domain.CodeInvalidFormat},
+		{name: "zero limit", q: dto.ListQuery{Limit: "0"}, field: "limit", code: domain.CodeInvalidFormat},
+		{name: "negative offset", q: dto.ListQuery{Offset: "-1"}, field: "offset", code: domain.CodeInvalidFormat},
+		{name: "bad price", q: dto.ListQuery{PriceMin: "abc"}, field: "price_min", code: domain.CodeInvalidFormat},
+		{name: "negative price", q: dto.ListQuery{PriceMax: "-5"}, field: "price_max", code: domain.CodeInvalidFormat},
+		{name: "price range", q: dto.ListQuery{PriceMin: "500", PriceMax: "100"}, field: "price_max", code: domain.CodeOutOfRange},
+		{name: "bad month", q: dto.ListQuery{ActiveAt: "2025-07"}, field: "active_at", code: domain.CodeInvalidFormat},
+		{name: "start range", q: dto.ListQuery{StartFrom: "12-2025", StartTo: "01-2025"}, field: "start_to", code: domain.CodeOutOfRange},
+		{name: "bad time", q: dto.ListQuery{UpdatedFrom: "yesterday"}, field: "updated_from", code: domain.CodeInvalidFormat},
+		{name: "bad status", q: dto.ListQuery{Status: "paused"}, field: "status", code: domain.CodeInvalidValue},
+		{name: "bad sort", q: dto.ListQuery{Sort: "-user_id"}, field: "sort", code: domain.CodeInvalidValue},
+		{name: "cursor with sort", q: dto.ListQuery{Cursor: ptr(""), Sort: "price"}, field: "sort", code: domain.CodeInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := listFilter(tt.q)
+			if tt.field != "" {
+				if fe := firstField(err); fe.Field != tt.field || fe.Code != tt.code {
+					t.Fatalf("listFilter() error = %v, want %s on %s", err, tt.code, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("listFilter() error = %v", err)
+			}
+			tt.check(t, f)
+		})
+	}
+
+	if _, err := listFilter(dto.ListQuery{Cursor: ptr("!!!")}); !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Errorf("bad cursor: error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestCheckIntroPhases(t *testing.T) {
+	many := make([]dto.IntroPhase, maxIntroPhases+1)
+	for i := range many {
+		many[i] = dto.IntroPhase{Months: 1}
+	}
+	tests := []struct {
+		name   string
+		in     []dto.IntroPhase
+		want   []domain.IntroPhase
+		fields []string // поля с ошибкой
+	}{
+		{name: "none"},
+		{name: "empty", in: []dto.IntroPhase{}},
+		{name: "trial and intro", in: []dto.IntroPhase{{Months: 1}, {Months: 3, Price: 199}},
+			want: []domain.IntroPhase{{Months: 1}, {Months: 3, Price: 199}}},
+		{name: "zero months", in: []dto.IntroPhase{{Months: 1}, {Months: 0, Price: 199}},
+			fields: []string{"intro_phases[1].months"}},
+		{name: "negative price and months", in: []dto.IntroPhase{{Months: -1, Price: -5}},
+			fields: []string{"intro_phases[0].months", "intro_phases[0].price"}},
+		{name: "too many", in: many, fields: []string{"intro_phases"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v domain.ValidationError
+			got := checkIntroPhases(tt.in, &v)
+			fields := make([]string, 0, len(v.Fields))
+			for _, f := range v.Fields {
+				fields = append(fields, f.Field)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Fatalf("errors on %v, want %v", fields, tt.fields)
+			}
+			if tt.fields == nil && !slices.Equal(got, tt.want) {
+				t.Errorf("checkIntroPhases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Фазы помесячные при любом периоде оплаты
+func TestIntroPhasesAnyBillingPeriod(t *testing.T) {
+	for _, bp := range []string{"weekly", "monthly", "quarterly", "yearly"} {
+		sub, err := newSubscription(dto.CreateSubscriptionRequest{
+			ServiceName: "Yandex Plus", Price: 400, BillingPeriod: bp, UserID: "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+			StartDate: "07-2025", IntroPhases: []dto.IntroPhase{{Months: 1}, {Months: 2, Price: 99}},
+		})
+		if err != nil {
+			t.Errorf("%s: %v", bp, err)
+			continue
+		}
+		if len(sub.IntroPhases) != 2 || string(sub.BillingPeriod) != bp {
+			t.Errorf("%s: got %+v", bp, sub)
+		}
+	}
+}
+
+func TestCheckStarted(t *testing.T) {
+	now := time.Now().UTC()
+	started := &domain.Subscription{Price: 400, Currency: "RUB", BillingPeriod: domain.BillingMonthly,
+		StartDate: domain.MonthStart(now).AddDate(0, -3, 0)}
+	future := &domain.Subscription{Price: 400, Currency: "RUB", BillingPeriod: domain.BillingMonthly,
+		StartDate: domain.MonthStart(now).AddDate(0, 3, 0)}
+	with := func(cur *domain.Subscription, change func(*domain.Subscription)) *domain.Subscription {
+		next := *cur
+		change(&next)
+		return &next
+	}
+	tests := []struct {
+		name  string
+		cur   *domain.Subscription
+		next  *domain.Subscription
+		prior string   // поле, по которому ошибка уже есть
+		want  []string // поля с ошибкой
+	}{
+		{name: "nothing changed", cur: started, next: with(started, func(*domain.Subscription) {})},
+		{name: "other fields", cur: started, next: with(started, func(s *domain.Subscription) {
+			s.ServiceName, s.EndDate = "Netflix", ptr(now)
+		})},
+		{name: "price", cur: started, next: with(started, func(s *domain.Subscription) { s.Price = 500 }), want: []string{"price"}},
+		{name: "currency", cur: started, next: with(started, func(s *domain.Subscription) { s.Currency = "USD" }), want: []string{"currency"}},
+		{name: "billing period", cur: started, next: with(started, func(s *domain.Subscription) {
+			s.BillingPeriod = domain.BillingYearly
+		}), want: []string{"billing_period"}},
+		{name: "start date", cur: started, next: with(started, func(s *domain.Subscription) {
+			s.StartDate = s.StartDate.AddDate(0, 0, 9)
+		}), want: []string{"start_date"}},
+		{name: "all at once", cur: started, next: with(started, func(s *domain.Subscription) {
+			s.Price, s.Currency, s.BillingPeriod, s.StartDate = 500, "USD", domain.BillingWeekly, now
+		}), want: []string{"price", "currency", "billing_period", "start_date"}},
+		{name: "already invalid field skipped", cur: started, next: with(started, func(s *domain.Subscription) { s.Price = 0 }),
+			prior: "price", want: []string{"price"}},
+		{name: "not started yet", cur: future, next: with(future, func(s *domain.Subscription) {
+			s.Price, s.Currency, s.BillingPeriod, s.StartDate = 500, "USD", domain.BillingWeekly, now
+		})},
+		{name: "started a second ago", cur: with(started, func(s *domain.Subscription) { s.StartDate = now.Add(-time.Second) }),
+			next: with(started, func(s *domain.Subscription) { s.Price = 500; s.StartDate = now.Add(-time.Second) }),
+			want: []string{"price"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v domain.ValidationError
+			if tt.prior != "" {
+				v.Add(tt.prior, domain.CodeOutOfRange, "bad")
+			}
+			checkStarted(tt.cur, tt.next, &v)
+			got := make([]string, 0, len(v.Fields))
+			for _, f := range v.Fields {
+				got = append(got, f.Field)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("errors on %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRepo репозиторий в памяти для тестов сервиса: Get отдаёт sub, Patch запоминает патч
+// Остальные методы не реализованы и паникуют через nil-интерфейс
+type fakeRepo struct {
+	repo.SubscriptionRepository
+	sub   *domain.Subscription
+	patch *repo.SubscriptionPatch
+}
+
+func (f *fakeRepo) Get(_ context.Context, id string, _ bool) (*domain.Subscription, error) {
+	if f.sub == nil || f.sub.ID != id {
+		return nil, domain.ErrNotFound
+	}
+	cp := *f.sub
+	return &cp, nil
+}
+
+func (f *fakeRepo) Patch(_ context.Context, id string, p repo.SubscriptionPatch) (*domain.Subscription, error) {
+	f.patch = &p
+	return f.Get(context.Background(), id, false)
+}
+
+func TestPatch(t *testing.T) {
+	const id = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	end := date(2099, 6, 1)
+	sub := func() *domain.Subscription {
+		return &domain.Subscription{ID: id, ServiceName: "Yandex Plus", Price: 400, Currency: "RUB",
+			BillingPeriod: domain.BillingMonthly, UserID: id, StartDate: date(2099, 1, 1), EndDate: &end, Version: 3}
+	}
+	startedSub := func() *domain.Subscription {
+		s := sub()
+		s.StartDate = date(2020, 1, 1)
+		return s
+	}
+	tests := []struct {
+		name    string
+		cur     func() *domain.Subscription // nil — sub
+		body    string
+		version int
+		check   func(t *testing.T, p repo.SubscriptionPatch)
+		field   string // поле ошибки валидации
+		wantErr error  // доменная ошибка вне валидации
+	}{
+		{
+			name: "empty patch changes nothing",
+			body: `{}`,
+			check: func(t *testing.T, p repo.SubscriptionPatch) {
+				if p.ServiceName != nil || p.Price != nil || p.Currency != nil || p.BillingPeriod != nil ||
+					p.UserID != nil || p.StartDate != nil || p.SetEndDate || p.DayPrecision != nil || p.IntroPhases != nil {
+					t.Errorf("patch = %+v, want empty", p)
+				}
+			},
+		},
+		{
+			name: "only given fields", body: `{"service_name":"Netflix","currency":"usd"}`, version: 3,
+			check: func(t *testing.T, p repo.SubscriptionPatch) {
+				if *p.ServiceName != "Netflix" || *p.Currency != "USD" || p.Price != nil || p.SetEndDate || p.Version != 3 {
+					t.Errorf("patch = %+v", p)
+				}
+			},
+		},
+		{
+			name: "null end date makes open-ended", body: `{"end_date":null}`,
+			check: func(t *testing.T, p repo.SubscriptionPatch) {
+				if !p.SetEndDate || p.EndDate != nil {
+					t.Errorf("patch = %+v, want end_date cleared", p)
+				}
+			},
+		},
+		{
+			name: "end date kept when start changes", body: `{"start_date":"03-2099"}`,
+			check: func(t *testing.T, p repo.SubscriptionPatch) {
+				if !p.StartDate.Equal(date(2099, 3, 1)) || p.SetEndDate {
+					t.Errorf("patch = %+v", p)
+				}
+			},
+		},
+		{name: "null required field", body: `{"service_name":null}`, field: "service_name"},
+		{name: "empty service name", body: `{"service_name":""}`, field: "service_name"},
+		{name: "bad price", body: `{"price":0}`, field: "price"},
+		{name: "null price", body: `{"price":null}`, field: "price"},
+		{name: "bad currency", body: `{"currency":"rubles"}`, field: "currency"},
+		{name: "bad billing period", body: `{"billing_period":"daily"}`, field: "billing_period"},
+		{name: "bad user", body: `{"user_id":"42"}`, field: "user_id"},
+		{name: "end before stored start", body: `{"end_date":"12-2098"}`, field: "end_date"},
+		{name: "start after stored end", body: `{"start_date":"07-2099"}`, field: "end_date"},
+		{name: "stale version", body: `{"price":500}`, version: 2, wantErr: domain.ErrConflict},
+		{name: "started price", cur: startedSub, body: `{"price":500}`, field: "price"},
+		{name: "started currency", cur: startedSub, body: `{"currency":"USD"}`, field: "currency"},
+		{name: "started billing period", cur: startedSub, body: `{"billing_period":"yearly"}`, field: "billing_period"},
+		{name: "started start date", cur: startedSub, body: `{"start_date":"2020-01-15"}`, field: "start_date"},
+		{
+			name: "started end date and name", cur: startedSub, body: `{"service_name":"Netflix","end_date":"2099-06-15","price":400}`,
+			check: func(t *testing.T, p repo.SubscriptionPatch) {
+				if *p.ServiceName != "Netflix" || !p.SetEndDate || !p.EndDate.Equal(date(2099, 6, 15)) || !*p.DayPrecision {
+					t.Errorf("patch = %+v", p)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in dto.PatchSubscriptionRequest
+			if err := json.Unmarshal([]byte(tt.body), &in); err != nil {
+				t.Fatal(err)
+			}
+			cur := sub
+			if tt.cur != nil {
+				cur = tt.cur
+			}
+			r := &fakeRepo{sub: cur()}
+			_, err := New(r, domain.RoundHalfUp).Patch(context.Background(), id, tt.version, in)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) || r.patch != nil {
+					t.Fatalf("Patch() error = %v, want %v without writing", err, tt.wantErr)
+				}
+			case tt.field != "":
+				var v *domain.ValidationError
+				if !errors.As(err, &v) || !v.Has(tt.field) || r.patch != nil {
+					t.Fatalf("Patch() error = %v, want error on %s without writing", err, tt.field)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Patch() error = %v", err)
+				}
+				tt.check(t, *r.patch)
+			}
+		})
+	}
+
+	if _, err := New(&fakeRepo{}, domain.RoundHalfUp).Patch(context.Background(), id, 0, dto.PatchSubscriptionRequest{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("missing subscription: error = %v, want ErrNotFound", err)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func fmtTime(t *time.Time) string {
+	if t == nil {
+		return "<nil>"
+	}
+	return t.Format(time.DateOnly)
+}
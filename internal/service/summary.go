@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/domain"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/dto"
+	"github.com/AlexAnd012/-Effective-Mobile.git/internal/repo"
+)
+
+// upcomingEndsLimit сколько ближайших окончаний отдаём в сводке
+const upcomingEndsLimit = 10
+
+// UserSummary Сводка пользователя одним запросом к БД: активные подписки и run-rate текущего месяца,
+// расходы за всё время, самый дорогой сервис и ближайшие окончания
+// Пользователь без подписок получает нулевую сводку
+func (s *Service) UserSummary(ctx context.Context, userID, currency string) (dto.UserSummaryResponse, error) {
+	var v domain.ValidationError
+	checkUserID(userID, &v)
+	if err := v.Err(); err != nil {
+		return dto.UserSummaryResponse{}, err
+	}
+	currency, err := parseCurrency(currency)
+	if err != nil {
+		return dto.UserSummaryResponse{}, err
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// From нулевой: расчёт обрезается датой начала каждой подписки
+	sum, err := s.repo.UserSummary(ctx, repo.CostFilter{
+		From: time.Time{}, To: domain.MonthStart(now), UserID: &userID, Currency: currency, Rounding: s.rounding,
+	}, today, upcomingEndsLimit)
+	if err != nil {
+		return dto.UserSummaryResponse{}, err
+	}
+
+	res := dto.UserSummaryResponse{
+		UserID:              userID,
+		Currency:            currency,
+		ActiveSubscriptions: sum.ActiveSubscriptions,
+		MonthlyRunRate:      sum.MonthlyRunRate,
+		LifetimeSpend:       sum.LifetimeSpend,
+		UpcomingEnds:        make([]dto.UserUpcomingEnd, 0, len(sum.UpcomingEnds)),
+	}
+	if sum.TopService != nil {
+		res.TopService = &dto.UserTopService{ServiceName: sum.TopService.ServiceName, MonthlyRunRate: sum.TopService.Total}
+	}
+	for _, e := range sum.UpcomingEnds {
+		res.UpcomingEnds = append(res.UpcomingEnds, dto.UserUpcomingEnd{
+			ID:          e.SubscriptionID,
+			ServiceName: e.ServiceName,
+			EndDate:     formatDate(e.EndDate, e.DayPrecision),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,4 @@
+-- Период оплаты подписки: price — стоимость одного периода
+alter table subscriptions
+    add column if not exists billing_period text not null default 'monthly'
+        check (billing_period in ('weekly', 'monthly', 'quarterly', 'yearly'));
@@ -0,0 +1,13 @@
+-- Валюта цены подписки (ISO 4217), по умолчанию рубли
+alter table subscriptions
+    add column if not exists currency char(3) not null default 'RUB'
+        check (currency ~ '^[A-Z]{3}$');
+
+-- Курсы валют к рублю: сколько рублей стоит 1 единица валюты
+-- курс действует с month до следующей записи по этой валюте
+create table if not exists exchange_rates (
+currency char(3) not null check (currency ~ '^[A-Z]{3}$' and currency <> 'RUB'),
+month date not null check (month = date_trunc('month', month)),
+rate numeric(18, 6) not null check (rate > 0),
+primary key (currency, month)
+);
@@ -0,0 +1,8 @@
+-- История цен: с effective_from действует новая цена, subscriptions.price — цена с start_date
+create table if not exists subscription_price_changes (
+id uuid primary key default gen_random_uuid(),
+subscription_id uuid not null references subscriptions(id) on delete cascade,
+effective_from date not null check (effective_from = date_trunc('month', effective_from)),
+price int not null check (price > 0),
+unique (subscription_id, effective_from)
+);
@@ -0,0 +1,3 @@
+-- Версия записи для оптимистичной блокировки: растёт на 1 при каждом изменении, отдаётся как ETag
+alter table subscriptions
+    add column if not exists version int not null default 1;
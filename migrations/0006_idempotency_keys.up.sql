@@ -0,0 +1,13 @@
+-- Ответы на POST с заголовком Idempotency-Key: повтор с тем же ключом получает сохранённый ответ
+-- status null — первый запрос ещё выполняется
+create table if not exists idempotency_keys (
+key text primary key,
+request_hash text not null,
+status int null,
+headers jsonb null,
+body bytea null,
+created_at timestamptz not null default now(),
+expires_at timestamptz not null
+);
+
+create index if not exists ix_idempotency_expires on idempotency_keys(expires_at);
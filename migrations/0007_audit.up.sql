@@ -0,0 +1,10 @@
+-- Кто и когда создал и последним изменил подписку
+-- created_by/updated_by — идентификатор вызывающего, пока нет аутентификации — request ID
+alter table subscriptions
+    add column if not exists created_at timestamptz not null default now(),
+    add column if not exists updated_at timestamptz not null default now(),
+    add column if not exists created_by text null,
+    add column if not exists updated_by text null;
+
+create index if not exists ix_subs_created_at on subscriptions(created_at);
+create index if not exists ix_subs_updated_at on subscriptions(updated_at);
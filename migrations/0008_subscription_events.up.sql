@@ -0,0 +1,14 @@
+-- Журнал изменений подписок: состояние до и после каждого create/update/delete
+-- Пишется тем же запросом, что и изменение, без внешнего ключа, чтобы история удалённых подписок сохранялась
+create table if not exists subscription_events (
+id bigserial primary key,
+subscription_id uuid not null,
+action text not null check (action in ('create', 'update', 'delete')),
+before jsonb null,
+after jsonb null,
+actor text null,
+request_id text null,
+created_at timestamptz not null default now()
+);
+
+create index if not exists ix_sub_events_subscription on subscription_events(subscription_id, id);
@@ -0,0 +1,10 @@
+-- Мягкое удаление: DELETE ставит deleted_at, строка остаётся для истории расходов
+-- Окончательно строки удаляет очистка после срока хранения
+alter table subscriptions add column if not exists deleted_at timestamptz null;
+
+create index if not exists ix_subs_deleted_at on subscriptions(deleted_at) where deleted_at is not null;
+
+-- В журнале появляются восстановление и окончательное удаление
+alter table subscription_events drop constraint if exists subscription_events_action_check;
+alter table subscription_events add constraint subscription_events_action_check
+    check (action in ('create', 'update', 'delete', 'restore', 'purge'));
@@ -0,0 +1,12 @@
+-- Приостановки подписки: месяцы from_month..to_month включительно не оплачиваются
+-- to_month = NULL — пауза до возобновления
+create table if not exists subscription_pauses (
+id uuid primary key default gen_random_uuid(),
+subscription_id uuid not null references subscriptions(id) on delete cascade,
+from_month date not null check (from_month = date_trunc('month', from_month)),
+to_month date null check (to_month = date_trunc('month', to_month)),
+created_at timestamptz not null default now(),
+check (to_month is null or to_month >= from_month)
+);
+
+create index if not exists ix_sub_pauses_subscription on subscription_pauses(subscription_id, from_month);
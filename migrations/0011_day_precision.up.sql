@@ -0,0 +1,3 @@
+-- Точность дат подписки: false — месяцы (даты — первые числа месяцев, end_date — месяц окончания),
+-- true — дни (start_date — первый день, end_date — последний оплачиваемый день), неполные месяцы считаются по дням
+alter table subscriptions add column if not exists day_precision boolean not null default false;
@@ -0,0 +1,3 @@
+-- Вводные периоды подписки: [{"months": 1, "price": 0}, {"months": 3, "price": 199}]
+-- Фазы идут по порядку от start_date, их цена заменяет обычную в этих месяцах
+alter table subscriptions add column if not exists intro_phases jsonb not null default '[]'::jsonb;
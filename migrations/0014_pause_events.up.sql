@@ -0,0 +1,4 @@
+-- Паузы меняют списания и тоже пишутся в журнал: before/after — строка subscription_pauses
+alter table subscription_events drop constraint if exists subscription_events_action_check;
+alter table subscription_events add constraint subscription_events_action_check
+    check (action in ('create', 'update', 'delete', 'restore', 'purge', 'price_change', 'pause', 'resume', 'pause_delete'));